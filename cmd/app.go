@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"profile-service/internal/adapter/blizzard"
+	"profile-service/internal/adapter/database"
+	"profile-service/internal/adapter/events"
+	"profile-service/internal/usecase"
+	"profile-service/pkg/config"
+	dbpool "profile-service/pkg/db"
+	logger "profile-service/pkg/log"
+	"profile-service/pkg/metrics"
+	"profile-service/pkg/slug"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// app holds the wiring shared by the server and the maintenance commands.
+type app struct {
+	cfg       *config.Config
+	log       *logrus.Logger
+	pool      *pgxpool.Pool
+	replica   *dbpool.Replica
+	metrics   *metrics.Metrics
+	dbAd      database.PostgresRepository
+	blizzAd   blizzard.BlizzardRepository
+	profileUc usecase.ProfileUsecase
+	watcher   *config.Watcher
+}
+
+func loadBase() (*config.Config, *logrus.Logger) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	log := logger.InitLogger(cfg)
+	log.WithField("config", cfg.Redacted()).Info("effective config")
+
+	return cfg, log
+}
+
+func openPool(ctx context.Context, cfg *config.Config, log *logrus.Logger) (*pgxpool.Pool, error) {
+	pool, err := dbpool.InitDBPool(dbpool.BuildDSN(cfg), cfg, log, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("init db pool: %w", err)
+	}
+	return pool, nil
+}
+
+func newApp(ctx context.Context, cfg *config.Config, log *logrus.Logger, pool *pgxpool.Pool) (*app, error) {
+	m := metrics.New()
+	m.RegisterPool(pool, "primary")
+
+	var replica *dbpool.Replica
+	if cfg.DB.ReplicaDSN != "" {
+		var err error
+		replica, err = dbpool.NewReplica(ctx, cfg.DB.ReplicaDSN, cfg, log)
+		if err != nil {
+			return nil, fmt.Errorf("init replica pool: %w", err)
+		}
+		m.RegisterPool(replica.Raw(), "replica")
+	}
+
+	dbAd := database.NewPostgresRepository(pool, replica, log)
+	blizzAd := blizzard.NewBlizzardRepository(cfg, dbAd, m, log)
+
+	realms := slug.NewRealmIndex()
+	storedRealms, err := dbAd.GetRealms(ctx)
+	if err != nil {
+		log.WithError(err).Warn("failed load realm index")
+	} else if len(storedRealms) > 0 {
+		realms.Load(storedRealms)
+	}
+
+	profileUc := usecase.NewProfileUsecase(dbAd, blizzAd, realms, cfg, log)
+
+	watcher := config.NewWatcher(cfg, log)
+	watcher.Subscribe(func(c *config.Config) { logger.SetLevel(log, c.Logger.Level) })
+	watcher.Subscribe(blizzAd.ApplyConfig)
+	watcher.Subscribe(profileUc.ApplyConfig)
+
+	return &app{
+		cfg:       cfg,
+		log:       log,
+		pool:      pool,
+		replica:   replica,
+		metrics:   m,
+		dbAd:      dbAd,
+		blizzAd:   blizzAd,
+		profileUc: profileUc,
+		watcher:   watcher,
+	}, nil
+}
+
+func newPublisher(cfg *config.Config, pool *pgxpool.Pool, log *logrus.Logger) events.EventPublisher {
+	switch cfg.Events.Publisher {
+	case "postgres":
+		return events.NewPostgresPublisher(pool, cfg.Events.Channel, log)
+	case "memory":
+		// Meant for tests: events pile up in process memory and reach no consumer.
+		log.Warn("events.publisher is memory: domain events are marked published but never leave this process, do not use it outside local runs")
+		return events.NewMemoryPublisher()
+	default:
+		return events.NewNopPublisher()
+	}
+}
+
+func (a *app) Close() {
+	if a.replica != nil {
+		a.replica.Close()
+	}
+}
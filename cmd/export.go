@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"profile-service/internal/entity"
+)
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	blizzardID := fs.String("blizzard-id", "", "export only this account")
+	out := fs.String("out", "", "output file, stdout when empty")
+	fs.Parse(args)
+
+	return withApp(func(ctx context.Context, a *app) error {
+		var w io.Writer = os.Stdout
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return fmt.Errorf("create %s: %w", *out, err)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		buf := bufio.NewWriter(w)
+		enc := json.NewEncoder(buf)
+		count := 0
+		err := a.dbAd.ExportCharacters(ctx, *blizzardID, func(char entity.Character) error {
+			count++
+			return enc.Encode(char)
+		})
+		if err != nil {
+			return err
+		}
+		if err := buf.Flush(); err != nil {
+			return fmt.Errorf("write export: %w", err)
+		}
+
+		a.log.Infof("Exported %d characters", count)
+		return nil
+	})
+}
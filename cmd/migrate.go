@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"profile-service/migrations"
+	"strconv"
+	"time"
+
+	"github.com/avast/retry-go"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	migrationLockKey     int64 = 0x70726f66696c65
+	migrationLockTimeout       = 2 * time.Minute
+
+	migrationsApply  = "apply"
+	migrationsVerify = "verify"
+	migrationsSkip   = "skip"
+)
+
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New("expected up, down, version, verify or force")
+	}
+	sub, args := args[0], args[1:]
+
+	fs := flag.NewFlagSet("migrate "+sub, flag.ExitOnError)
+	steps := fs.Int("steps", 1, "number of migrations to roll back (down only)")
+	all := fs.Bool("all", false, "roll back every migration (down only)")
+	fs.Parse(args)
+
+	cfg, log := loadBase()
+
+	pool, err := openPool(context.Background(), cfg, log)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	switch sub {
+	case "up":
+		return runMigrations(pool, log)
+	case "down":
+		return withAdvisoryLock(context.Background(), pool, log, func() error {
+			return rollbackMigrations(pool, log, *steps, *all)
+		})
+	case "verify":
+		return verifyMigrations(pool, log)
+	case "version":
+		return withMigrate(pool, log, func(m *migrate.Migrate) error {
+			return printVersion(m)
+		})
+	case "force":
+		if fs.NArg() != 1 {
+			return errors.New("usage: migrate force VERSION")
+		}
+		version, err := strconv.Atoi(fs.Arg(0))
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", fs.Arg(0), err)
+		}
+		return withAdvisoryLock(context.Background(), pool, log, func() error {
+			return withMigrate(pool, log, func(m *migrate.Migrate) error {
+				if err := m.Force(version); err != nil {
+					return fmt.Errorf("migrate force failed: %w", err)
+				}
+				return printVersion(m)
+			})
+		})
+	default:
+		return fmt.Errorf("unknown migrate command %q", sub)
+	}
+}
+
+func rollbackMigrations(pool *pgxpool.Pool, log *logrus.Logger, steps int, all bool) error {
+	if !all && steps < 1 {
+		return fmt.Errorf("--steps must be at least 1, got %d", steps)
+	}
+
+	return withMigrate(pool, log, func(m *migrate.Migrate) error {
+		var err error
+		if all {
+			err = m.Down()
+		} else {
+			err = m.Steps(-steps)
+		}
+		if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+			return fmt.Errorf("migrate down failed: %w", err)
+		}
+		return printVersion(m)
+	})
+}
+
+func printVersion(m *migrate.Migrate) error {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Println("no migrations applied")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get migrate version: %w", err)
+	}
+
+	fmt.Printf("version %d, dirty %t\n", version, dirty)
+	return nil
+}
+
+func withMigrate(pool *pgxpool.Pool, log *logrus.Logger, fn func(m *migrate.Migrate) error) error {
+	db := stdlib.OpenDBFromPool(pool)
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.WithError(err).Warn("closing migrate db failed")
+		}
+	}()
+
+	m, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		srcErr, dbErr := m.Close()
+		if srcErr != nil {
+			log.WithError(srcErr).Warn("migrate source close failed")
+		}
+		if dbErr != nil {
+			log.WithError(dbErr).Warn("migrate db close failed")
+		}
+	}()
+
+	return fn(m)
+}
+
+// latestVersion returns the highest migration version embedded in the binary.
+func latestVersion() (uint, error) {
+	versions, err := migrations.Versions()
+	if err != nil {
+		return 0, err
+	}
+	return versions[len(versions)-1], nil
+}
+
+// verifyMigrations fails unless the schema is clean and exactly at the
+// latest embedded version. It never changes the database.
+func verifyMigrations(pool *pgxpool.Pool, log *logrus.Logger) error {
+	latest, err := latestVersion()
+	if err != nil {
+		return err
+	}
+
+	return withMigrate(pool, log, func(m *migrate.Migrate) error {
+		version, dirty, err := m.Version()
+		if errors.Is(err, migrate.ErrNilVersion) {
+			return fmt.Errorf("no migrations applied, expected version %d", latest)
+		}
+		if err != nil {
+			return fmt.Errorf("get migrate version: %w", err)
+		}
+		if dirty {
+			return fmt.Errorf("migration %d is dirty, fix it with migrate force", version)
+		}
+		if version != latest {
+			return fmt.Errorf("schema is at version %d, binary expects %d", version, latest)
+		}
+
+		log.WithField("version", version).Info("Schema version verified")
+		return nil
+	})
+}
+
+// withAdvisoryLock serializes fn across every instance sharing the database,
+// so replicas starting together apply migrations one at a time.
+func withAdvisoryLock(ctx context.Context, pool *pgxpool.Pool, log *logrus.Logger, fn func() error) error {
+	lockCtx, cancel := context.WithTimeout(ctx, migrationLockTimeout)
+	defer cancel()
+
+	conn, err := pool.Acquire(lockCtx)
+	if err != nil {
+		return fmt.Errorf("acquire connection for migration lock: %w", err)
+	}
+	defer conn.Release()
+
+	log.Info("Waiting for migration lock...")
+	if _, err := conn.Exec(lockCtx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return fmt.Errorf("take migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey); err != nil {
+			log.WithError(err).Warn("release migration lock failed")
+		}
+	}()
+
+	return fn()
+}
+
+func runMigrations(pool *pgxpool.Pool, log *logrus.Logger) error {
+	return withAdvisoryLock(context.Background(), pool, log, func() error {
+		return applyMigrations(pool, log)
+	})
+}
+
+func applyMigrations(pool *pgxpool.Pool, log *logrus.Logger) error {
+	start := time.Now()
+
+	return withMigrate(pool, log, func(m *migrate.Migrate) error {
+		log.Info("Applying migrations...")
+
+		var migErr error
+		err := retry.Do(
+			func() error {
+				migErr = m.Up()
+				if migErr != nil && !errors.Is(migErr, migrate.ErrNoChange) {
+					return fmt.Errorf("migrate up failed: %w", migErr)
+				}
+				return nil
+			},
+			retry.Attempts(3),
+			retry.Delay(1*time.Second),
+		)
+		if err != nil {
+			return err
+		}
+
+		if errors.Is(migErr, migrate.ErrNoChange) {
+			log.Info("No migrations to apply")
+		}
+
+		version, dirty, err := m.Version()
+		if err != nil {
+			return fmt.Errorf("get migrate version: %w", err)
+		}
+		log.WithFields(logrus.Fields{
+			"duration": time.Since(start),
+			"version":  version,
+			"dirty":    dirty,
+		}).Info("Migrations succeeded")
+
+		return nil
+	})
+}
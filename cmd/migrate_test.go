@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"profile-service/internal/dbtest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+func testLogger() *logrus.Logger {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return log
+}
+
+func newEmptyPool(t *testing.T, dsn string) *pgxpool.Pool {
+	t.Helper()
+	pool, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestVerifyMigrations(t *testing.T) {
+	log := testLogger()
+	pool := newEmptyPool(t, dbtest.Database(t))
+
+	latest, err := latestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schemaVersion := func() (uint, bool) {
+		t.Helper()
+		var version uint
+		var dirty bool
+		err := withMigrate(pool, log, func(m *migrate.Migrate) error {
+			var err error
+			version, dirty, err = m.Version()
+			if errors.Is(err, migrate.ErrNilVersion) {
+				return nil
+			}
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return version, dirty
+	}
+	migrateTo := func(fn func(m *migrate.Migrate) error) {
+		t.Helper()
+		if err := withMigrate(pool, log, fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := verifyMigrations(pool, log); err == nil || !strings.Contains(err.Error(), "no migrations applied") {
+		t.Fatalf("verify on an empty database = %v", err)
+	}
+	// Verifying never migrates.
+	if version, _ := schemaVersion(); version != 0 {
+		t.Fatalf("verify moved an empty database to version %d", version)
+	}
+
+	migrateTo(func(m *migrate.Migrate) error { return m.Migrate(latest - 1) })
+	if err := verifyMigrations(pool, log); err == nil || !strings.Contains(err.Error(), "binary expects") {
+		t.Fatalf("verify one version behind = %v", err)
+	}
+	if version, _ := schemaVersion(); version != latest-1 {
+		t.Fatalf("verify moved the schema to version %d", version)
+	}
+
+	if err := runMigrations(pool, log); err != nil {
+		t.Fatalf("runMigrations: %v", err)
+	}
+	if err := verifyMigrations(pool, log); err != nil {
+		t.Fatalf("verify at the latest version: %v", err)
+	}
+
+	migrateTo(func(m *migrate.Migrate) error { return m.Force(int(latest)) })
+	if _, err := pool.Exec(context.Background(), "UPDATE schema_migrations SET dirty = true"); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyMigrations(pool, log); err == nil || !strings.Contains(err.Error(), "dirty") {
+		t.Fatalf("verify on a dirty schema = %v", err)
+	}
+	if _, dirty := schemaVersion(); !dirty {
+		t.Fatal("verify cleared the dirty flag")
+	}
+}
+
+func TestAdvisoryLockSerializesReplicas(t *testing.T) {
+	log := testLogger()
+	dsn := dbtest.Database(t)
+	ctx := context.Background()
+
+	// Each replica has its own pool, as separate processes would.
+	first, second := newEmptyPool(t, dsn), newEmptyPool(t, dsn)
+
+	held := make(chan struct{})
+	release := make(chan struct{})
+	firstDone := make(chan error, 1)
+	go func() {
+		firstDone <- withAdvisoryLock(ctx, first, log, func() error {
+			close(held)
+			<-release
+			return nil
+		})
+	}()
+	<-held
+
+	var secondRan atomic.Bool
+	secondDone := make(chan error, 1)
+	go func() {
+		secondDone <- withAdvisoryLock(ctx, second, log, func() error {
+			secondRan.Store(true)
+			return nil
+		})
+	}()
+
+	time.Sleep(200 * time.Millisecond)
+	if secondRan.Load() {
+		t.Fatal("second replica ran while the first held the lock")
+	}
+
+	close(release)
+	if err := <-firstDone; err != nil {
+		t.Fatalf("first replica: %v", err)
+	}
+	select {
+	case err := <-secondDone:
+		if err != nil {
+			t.Fatalf("second replica: %v", err)
+		}
+		if !secondRan.Load() {
+			t.Fatal("second replica returned without running")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second replica never got the lock after it was released")
+	}
+}
+
+func TestRunMigrationsFromConcurrentReplicas(t *testing.T) {
+	log := testLogger()
+	dsn := dbtest.Database(t)
+
+	latest, err := latestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 3)
+	for i := 0; i < cap(errs); i++ {
+		pool := newEmptyPool(t, dsn)
+		go func() { errs <- runMigrations(pool, log) }()
+	}
+	for i := 0; i < cap(errs); i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("replica failed to migrate: %v", err)
+		}
+	}
+
+	if err := verifyMigrations(newEmptyPool(t, dsn), log); err != nil {
+		t.Errorf("schema after concurrent startup: %v (want version %d)", err, latest)
+	}
+}
+
+func TestRollbackMigrationsRejectsNoSteps(t *testing.T) {
+	// Checked before the database is touched, so no pool is needed.
+	for _, steps := range []int{0, -2} {
+		if err := rollbackMigrations(nil, testLogger(), steps, false); err == nil || !strings.Contains(err.Error(), "--steps must be at least 1") {
+			t.Errorf("rollback of %d steps = %v", steps, err)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	profilev1 "profile-service/api/profile/v1"
+	"profile-service/internal/adapter/webhook"
+	"profile-service/internal/handler"
+	"profile-service/internal/usecase"
+	"profile-service/pkg/config"
+	"profile-service/pkg/tracing"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	mode := fs.String("migrations", "", "apply, verify or skip migrations at startup (default db.migrations)")
+	fs.Parse(args)
+
+	cfg, log := loadBase()
+	if *mode == "" {
+		*mode = cfg.DB.Migrations
+	}
+
+	ctx := context.Background()
+	shutdownTracing, err := tracing.Init(ctx, cfg, log)
+	if err != nil {
+		return fmt.Errorf("init tracing: %w", err)
+	}
+
+	pool, err := openPool(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	switch *mode {
+	case migrationsApply:
+		if err := runMigrations(pool, log); err != nil {
+			return fmt.Errorf("migrations failed: %w", err)
+		}
+	case migrationsVerify:
+		if err := verifyMigrations(pool, log); err != nil {
+			return fmt.Errorf("schema check failed: %w", err)
+		}
+	case migrationsSkip:
+		log.Warn("Skipping migrations check")
+	default:
+		return fmt.Errorf("unknown migrations mode %q", *mode)
+	}
+
+	a, err := newApp(ctx, cfg, log, pool)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	bgCtx, stopBackground := context.WithCancel(ctx)
+	defer stopBackground()
+	a.watcher.Start(bgCtx)
+	if a.replica != nil {
+		a.replica.Monitor(bgCtx, cfg.DB.ReplicaCheck)
+	}
+
+	healthUc := usecase.NewHealthUsecase(a.dbAd, a.blizzAd, log)
+
+	webhookUc := usecase.NewWebhookUsecase(a.dbAd, webhook.NewWebhookClient(cfg, a.metrics), cfg, log)
+	a.watcher.Subscribe(webhookUc.ApplyConfig)
+	if cfg.Webhooks.Enabled {
+		go webhookUc.Run(bgCtx)
+	}
+
+	eventUc := usecase.NewEventUsecase(a.dbAd, newPublisher(cfg, a.pool, log), cfg, log)
+	a.watcher.Subscribe(eventUc.ApplyConfig)
+	go eventUc.Run(bgCtx)
+
+	profileHandl := handler.NewProfileHandler(a.blizzAd, a.profileUc, log)
+	healthHandl := handler.NewHealthHandler(healthUc, log)
+	webhookHandl := handler.NewWebhookHandler(a.blizzAd, webhookUc, log)
+
+	router := gin.Default()
+	handler.SetupRoutes(router, profileHandl, healthHandl, webhookHandl, a.metrics, cfg, log)
+
+	log.Infof("Server starting on %s:%d", cfg.Server.Host, cfg.Server.Port)
+
+	srv := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler: router,
+	}
+
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("failed start server: %v", err)
+		}
+	}()
+
+	var grpcSrv *grpc.Server
+	var grpcHealth *health.Server
+	if cfg.GRPC.Enabled {
+		grpcSrv, grpcHealth, err = startGRPC(cfg, a, log)
+		if err != nil {
+			return err
+		}
+	}
+
+	healthHandl.SetReady(true)
+	log.Info("Server started")
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Info("Server shut down...")
+	healthHandl.SetReady(false)
+	if grpcHealth != nil {
+		grpcHealth.Shutdown()
+	}
+
+	// Keep serving while load balancers notice the failing readiness probe
+	// and stop routing new requests here.
+	if delay := cfg.Server.DrainDelay; delay > 0 {
+		log.WithField("delay", delay).Info("Draining before shutdown")
+		time.Sleep(delay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if grpcSrv != nil {
+		stopGRPC(shutdownCtx, grpcSrv)
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown server: %w", err)
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.WithError(err).Warn("failed flush traces")
+	}
+
+	log.Info("Server exited")
+	return nil
+}
+
+// startGRPC serves the internal ProfileService API on its own port, behind
+// service-token auth, next to the standard health service.
+func startGRPC(cfg *config.Config, a *app, log *logrus.Logger) (*grpc.Server, *health.Server, error) {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
+	if err != nil {
+		return nil, nil, fmt.Errorf("listen grpc: %w", err)
+	}
+
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		handler.GRPCLogging(log),
+		handler.GRPCAuth(cfg.GRPC.ServiceTokens, log),
+	))
+	profilev1.RegisterProfileServiceServer(srv, handler.NewProfileGRPCHandler(a.blizzAd, a.profileUc, log))
+
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus(profilev1.ProfileService_ServiceDesc.ServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(srv, healthSrv)
+
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			log.Fatalf("failed start grpc server: %v", err)
+		}
+	}()
+
+	log.Infof("gRPC server starting on :%d", cfg.GRPC.Port)
+	return srv, healthSrv, nil
+}
+
+// stopGRPC lets in-flight calls finish until ctx expires, then cuts them off.
+func stopGRPC(ctx context.Context, srv *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		srv.Stop()
+	}
+}
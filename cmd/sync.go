@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func runSync(args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	blizzardID := fs.String("blizzard-id", "", "account whose stored characters to refresh")
+	fs.Parse(args)
+
+	if *blizzardID == "" {
+		return errors.New("--blizzard-id is required")
+	}
+
+	return withApp(func(ctx context.Context, a *app) error {
+		batch, err := a.profileUc.SyncAccount(ctx, *blizzardID)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("synced %s: %d updated, %d unchanged, %d failed\n", *blizzardID, len(batch.Characters), batch.Unchanged, batch.Failed)
+		return nil
+	})
+}
+
+func runSyncGuild(args []string) error {
+	fs := flag.NewFlagSet("sync-guild", flag.ExitOnError)
+	realm := fs.String("realm", "", "realm name or slug")
+	guild := fs.String("slug", "", "guild name or slug")
+	fs.Parse(args)
+
+	if *realm == "" || *guild == "" {
+		return errors.New("--realm and --slug are required")
+	}
+
+	return withApp(func(ctx context.Context, a *app) error {
+		roster, err := a.profileUc.SyncGuild(ctx, *realm, *guild)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("synced guild %s-%s (%d): %d members\n", roster.RealmSlug, roster.NameSlug, roster.GuildID, len(roster.Members))
+		return nil
+	})
+}
+
+// withApp wires the application for a one-shot command. Ctrl+C cancels ctx
+// so an in-flight sync stops without writing partial results.
+func withApp(fn func(ctx context.Context, a *app) error) error {
+	cfg, log := loadBase()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := openPool(ctx, cfg, log)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	a, err := newApp(ctx, cfg, log, pool)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	return fn(ctx, a)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// The one-shot commands check their flags before loading the config or
+// touching the database.
+func TestCommandsRejectMissingFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func([]string) error
+		args []string
+		want string
+	}{
+		{name: "sync without an account", run: runSync, want: "--blizzard-id is required"},
+		{name: "sync-guild without a guild", run: runSyncGuild, args: []string{"--realm", "silvermoon"}, want: "--realm and --slug are required"},
+		{name: "sync-guild without a realm", run: runSyncGuild, args: []string{"--slug", "test-guild"}, want: "--realm and --slug are required"},
+		{name: "migrate without a subcommand", run: runMigrate, want: "expected up, down, version, verify or force"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(tt.args); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
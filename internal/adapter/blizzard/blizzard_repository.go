@@ -4,10 +4,19 @@ import (
 	"context"
 	"profile-service/internal/entity"
 	"profile-service/pkg/dto"
+	"profile-service/pkg/slug"
 )
 
 type BlizzardRepository interface {
-	GetCharacters(ctx context.Context, blizzAccess, jwtToken string) ([]entity.Character, []entity.Guild, error)
+	GetCharacters(ctx context.Context, blizzAccess, jwtToken string, conditional bool) (*entity.SyncBatch, error)
 	GetUserData(ctx context.Context, jwtToken string) (*dto.UserDTO, error)
 	GetBlizzardAccessToken(ctx context.Context, jwtToken string) (string, error)
+	GetKnownCharacters(ctx context.Context, blizzAccess string, known []entity.Character, conditional bool) (*entity.SyncBatch, error)
+	GetRealmIndex(ctx context.Context, blizzAccess string) ([]slug.Realm, error)
+	GetGuildRoster(ctx context.Context, blizzAccess, realmSlug, nameSlug string) (*entity.GuildRoster, error)
+	GetClientToken(ctx context.Context) (string, error)
+	RateLimitBudget() Budget
+	Available() bool
+	PingAuth(ctx context.Context) error
+	PingAPI(ctx context.Context) error
 }
@@ -9,62 +9,208 @@ import (
 	"net/http"
 	"net/url"
 	"profile-service/internal/entity"
+	"profile-service/pkg/config"
 	"profile-service/pkg/dto"
 	"profile-service/pkg/errors"
+	logger "profile-service/pkg/log"
+	"profile-service/pkg/metrics"
+	"profile-service/pkg/resilience"
+	"profile-service/pkg/slug"
+	"profile-service/pkg/workerpool"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"golang.org/x/time/rate"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var (
+	errNotModified = fmt.Errorf("not modified")
+	tracer         = otel.Tracer("profile-service/internal/adapter/blizzard")
+)
+
+type ValidatorStore interface {
+	GetHTTPValidators(ctx context.Context, urls []string) (map[string]entity.HTTPValidator, error)
+}
+
 type blizzardRepository struct {
-	client  *http.Client
-	log     *logrus.Logger
-	limiter *rate.Limiter
+	client       *http.Client
+	apiClient    *http.Client
+	oauthClient  *http.Client
+	probe        *http.Client
+	limiter      *rateLimitTransport
+	authBreaker  *resilience.Breaker
+	blizzBreaker *resilience.Breaker
+	oauthBreaker *resilience.Breaker
+	validators   ValidatorStore
+	pool         *workerpool.Pool
+	authURL      string
+	clientID     string
+	clientSecret string
+	log          *logrus.Logger
+
+	tokenMu        sync.Mutex
+	clientToken    string
+	clientTokenExp time.Time
 }
 
-func NewBlizzardRepository(log *logrus.Logger) *blizzardRepository {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-		Transport: &http.Transport{
-			MaxIdleConns:       10,
-			MaxConnsPerHost:    5,
-			IdleConnTimeout:    30 * time.Second,
-			DisableCompression: false,
+func NewBlizzardRepository(cfg *config.Config, validators ValidatorStore, m *metrics.Metrics, log *logrus.Logger) *blizzardRepository {
+	transport := &http.Transport{
+		MaxIdleConns:       10,
+		MaxConnsPerHost:    5,
+		IdleConnTimeout:    30 * time.Second,
+		DisableCompression: false,
+	}
+	limits := cfg.Blizzard.RateLimit
+	blizzTransport := otelhttp.NewTransport(transport, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return "blizzard " + blizzardEndpoint(r)
+	}))
+	authTransport := otelhttp.NewTransport(transport, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return "auth_service " + authEndpoint(r)
+	}))
+	oauthTransport := otelhttp.NewTransport(transport, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return "blizzard_oauth " + blizzardEndpoint(r)
+	}))
+
+	limiter := newRateLimitTransport(
+		m.Transport(blizzTransport, "blizzard", blizzardEndpoint),
+		limits.PerSecond, limits.Burst, limits.PerHour, limits.MaxRetries,
+		m.ObserveRateLimitWait, log,
+	)
+
+	res := cfg.Resilience
+	policy := resilience.RetryPolicy{
+		Attempts:  res.RetryAttempts,
+		Delay:     res.RetryDelay,
+		MaxJitter: res.RetryMaxJitter,
+	}
+	authBreaker := resilience.NewBreaker("auth_service", res.BreakerThreshold, res.BreakerCooldown, log)
+	blizzBreaker := resilience.NewBreaker("blizzard", res.BreakerThreshold, res.BreakerCooldown, log)
+	// Token requests go to oauth.battle.net: they neither spend the API quota
+	// nor say anything about the health of the API hosts.
+	oauthBreaker := resilience.NewBreaker("blizzard_oauth", res.BreakerThreshold, res.BreakerCooldown, log)
+
+	return &blizzardRepository{
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: resilience.NewTransport(m.Transport(authTransport, "auth_service", authEndpoint), authBreaker, policy),
 		},
+		apiClient: &http.Client{
+			Transport: resilience.NewTransport(limiter, blizzBreaker, policy),
+		},
+		oauthClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: resilience.NewTransport(m.Transport(oauthTransport, "blizzard_oauth", blizzardEndpoint), oauthBreaker, policy),
+		},
+		probe: &http.Client{
+			Timeout:   3 * time.Second,
+			Transport: transport,
+		},
+		limiter:      limiter,
+		authBreaker:  authBreaker,
+		blizzBreaker: blizzBreaker,
+		oauthBreaker: oauthBreaker,
+		validators:   validators,
+		pool:         workerpool.New(cfg.Blizzard.Workers, cfg.Blizzard.CharacterTimeout).WithQueueGauge(m.WorkerQueue()),
+		authURL:      strings.TrimSuffix(cfg.Auth.URL, "/"),
+		clientID:     cfg.Blizzard.ClientID,
+		clientSecret: cfg.Blizzard.ClientSecret,
+		log:          log,
 	}
-	return &blizzardRepository{
-		client:  client,
-		log:     log,
-		limiter: rate.NewLimiter(rate.Every(time.Second/50), 10),
+}
+
+// PingAuth checks auth_service's health endpoint directly, bypassing retries
+// and the circuit breaker.
+func (br *blizzardRepository) PingAuth(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", br.authURL+"/health", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := br.probe.Do(req)
+	if err != nil {
+		return err
 	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("auth_service health returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// PingAPI reports Blizzard as down while its circuit is open, otherwise it
+// checks the API host answers at all. The probe is unauthenticated so it
+// does not count against the hourly quota.
+func (br *blizzardRepository) PingAPI(ctx context.Context) error {
+	if !br.Available() {
+		return resilience.ErrCircuitOpen
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", "https://eu.api.blizzard.com/", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := br.probe.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("blizzard API returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ApplyConfig picks up reloadable Blizzard settings: rate limits, worker count
+// and the per-character timeout.
+func (br *blizzardRepository) ApplyConfig(cfg *config.Config) {
+	limits := cfg.Blizzard.RateLimit
+	br.limiter.SetLimits(limits.PerSecond, limits.Burst, limits.PerHour, limits.MaxRetries)
+	br.pool.Resize(cfg.Blizzard.Workers, cfg.Blizzard.CharacterTimeout)
+}
+
+func (br *blizzardRepository) RateLimitBudget() Budget {
+	return br.limiter.Budget()
+}
+
+func (br *blizzardRepository) Available() bool {
+	return br.blizzBreaker.State() != resilience.StateOpen
 }
 
 func (br *blizzardRepository) GetUserData(ctx context.Context, jwtToken string) (*dto.UserDTO, error) {
 	if jwtToken == "" {
-		br.log.Error("access header is missing")
+		br.logFor(ctx).Error("access header is missing")
 		return nil, errors.NewAppError("access token is empty", nil)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", "http://auth_service:8080/auth/user", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", br.authURL+"/auth/user", nil)
 	if err != nil {
-		br.log.WithError(err).Errorf("failed create get user request: %v ", err)
+		br.logFor(ctx).WithError(err).Errorf("failed create get user request: %v ", err)
 		return nil, errors.NewAppError("failed create get user request", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+jwtToken)
+	if id := logger.RequestID(ctx); id != "" {
+		req.Header.Set("X-Request-ID", id)
+	}
 
 	resp, err := br.client.Do(req)
 	if err != nil {
-		br.log.WithError(err).Error("failed get user response")
+		br.logFor(ctx).WithError(err).Error("failed get user response")
 		return nil, errors.NewAppError("failed get user response", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		br.log.WithFields(logrus.Fields{
+		br.logFor(ctx).WithFields(logrus.Fields{
 			"status": resp.StatusCode,
 			"body":   string(body),
 		}).Warn("bad response from auth service")
@@ -73,7 +219,7 @@ func (br *blizzardRepository) GetUserData(ctx context.Context, jwtToken string)
 
 	var userDTO dto.UserDTO
 	if err := json.NewDecoder(resp.Body).Decode(&userDTO); err != nil {
-		br.log.WithError(err).Error("failed to decode user response")
+		br.logFor(ctx).WithError(err).Error("failed to decode user response")
 		return nil, errors.NewAppError("failed to user profile", err)
 	}
 
@@ -82,15 +228,18 @@ func (br *blizzardRepository) GetUserData(ctx context.Context, jwtToken string)
 
 func (br *blizzardRepository) GetBlizzardAccessToken(ctx context.Context, jwtToken string) (string, error) {
 	if jwtToken == "" {
-		br.log.Error("access header is missing")
+		br.logFor(ctx).Error("access header is missing")
 		return "", errors.NewAppError("access token is empty", nil)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", "http://auth_service:8080/auth/blizzard/token", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", br.authURL+"/auth/blizzard/token", nil)
 	if err != nil {
 		return "", errors.NewAppError("failed create get blizzard token request", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+jwtToken)
+	if id := logger.RequestID(ctx); id != "" {
+		req.Header.Set("X-Request-ID", id)
+	}
 
 	resp, err := br.client.Do(req)
 	if err != nil {
@@ -113,210 +262,331 @@ func (br *blizzardRepository) GetBlizzardAccessToken(ctx context.Context, jwtTok
 	return tokenResp.AccessToken, nil
 }
 
-func (br *blizzardRepository) GetCharacters(ctx context.Context, blizzAccess, jwtToken string) ([]entity.Character, []entity.Guild, error) {
+func (br *blizzardRepository) GetCharacters(ctx context.Context, blizzAccess, jwtToken string, conditional bool) (*entity.SyncBatch, error) {
+	ctx, span := tracer.Start(ctx, "blizzard.GetCharacters", trace.WithAttributes(attribute.Bool("conditional", conditional)))
+	defer span.End()
+
 	if blizzAccess == "" {
-		br.log.Error("access header is missing")
-		return nil, nil, errors.NewAppError("access token is empty", nil)
+		br.logFor(ctx).Error("access header is missing")
+		return nil, errors.NewAppError("access token is empty", nil)
 	}
 	if jwtToken == "" {
-		br.log.Error("jwt token is missing")
-		return nil, nil, errors.NewAppError("access token is empty", nil)
+		br.logFor(ctx).Error("jwt token is missing")
+		return nil, errors.NewAppError("access token is empty", nil)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://eu.api.blizzard.com/profile/user/wow?namespace=profile-eu&locale=ru_RU", nil)
+	req, err := newAPIRequest(ctx, blizzAccess, "https://eu.api.blizzard.com/profile/user/wow?namespace=profile-eu&locale=ru_RU", nil)
 	if err != nil {
-		br.log.WithError(err).Errorf("failed create characters request: %v ", err)
-		return nil, nil, errors.NewAppError("failed create characters request", err)
+		br.logFor(ctx).WithError(err).Errorf("failed create characters request: %v ", err)
+		return nil, errors.NewAppError("failed create characters request", err)
 	}
-	req.Header.Set("Authorization", "Bearer "+blizzAccess)
 
-	resp, err := br.client.Do(req)
+	resp, err := br.apiClient.Do(req)
 	if err != nil {
-		br.log.WithError(err).Error("failed get characters response")
-		return nil, nil, errors.NewAppError("failed get characters response", err)
+		br.logFor(ctx).WithError(err).Error("failed get characters response")
+		return nil, errors.NewAppError("failed get characters response", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		br.log.WithFields(logrus.Fields{
+		br.logFor(ctx).WithFields(logrus.Fields{
 			"status": resp.StatusCode,
 			"body":   string(body),
 		}).Warn("bad response from API")
-		return nil, nil, errors.NewAppError("bad response", err)
+		return nil, apiError(resp.StatusCode)
 	}
 
 	var profile dto.BlizzardProfileResponse
 	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
-		br.log.WithError(err).Error("failed to decode profile response")
-		return nil, nil, errors.NewAppError("failed to decode profile", err)
+		br.logFor(ctx).WithError(err).Error("failed to decode profile response")
+		return nil, errors.NewAppError("failed to decode profile", err)
 	}
 
 	user, err := br.GetUserData(ctx, jwtToken)
 	if err != nil {
-		br.log.WithError(err).Error("failed parse user data")
-		return nil, nil, err
-	}
-
-	characters := make([]entity.Character, 0)
-	guilds := make([]entity.Guild, 0)
-	mu := &sync.Mutex{}
-
-	type job struct {
-		char dto.CharacterSummary
-	}
-
-	jobs := make(chan job, 10)
-	wg := sync.WaitGroup{}
-
-	workerCount := 3
-	for i := 0; i < workerCount; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for j := range jobs {
-				char := j.char
-
-				mythScore, err := br.getMythicScore(ctx, blizzAccess, char.Realm.Slug, char.Name)
-				if err != nil {
-					br.log.WithError(err).WithFields(logrus.Fields{
-						"character": char.Name,
-						"realm":     char.Realm.Slug,
-					}).Warn("failed to get mythic score")
-					mythScore = 0
-				}
-
-				details, err := br.getCharacterDetails(ctx, blizzAccess, char.Realm.Slug, char.Name)
-				if err != nil {
-					br.log.WithError(err).WithFields(logrus.Fields{
-						"character": char.Name,
-						"realm":     char.Realm.Slug,
-					}).Warn("failed to get character details")
-					details = &dto.CharacterDetailsResponse{
-						Spec: struct {
-							Name string "json:\"name\""
-						}{"Unknown"},
-						Ilvl: 0,
-						Guild: struct {
-							Name  string "json:\"name\""
-							ID    int    "json:\"id\""
-							Realm struct {
-								Name string "json:\"name\""
-								Slug string "json:\"slug\""
-							} "json:\"realm\""
-							Faction struct {
-								Name string "json:\"name\""
-							} "json:\"faction\""
-						}{},
-					}
-				}
-
-				newChar := entity.Character{
-					CharacterID: details.ID,
-					BlizzardID:  user.ID,
-					Battletag:   user.Battletag,
-					Name:        char.Name,
-					Race:        char.PlayableRace.Name,
-					Realm:       char.Realm.Name,
-					Faction:     char.Faction.Name,
-					Class:       char.PlayableClass.Name,
-					Spec:        details.Spec.Name,
-					Lvl:         char.Level,
-					Ilvl:        details.Ilvl,
-					Guild:       details.Guild.Name,
-					MythicScore: mythScore,
-					IsMain:      false,
-				}
-
-				mu.Lock()
-				characters = append(characters, newChar)
-				if details.Guild.ID != 0 && details.Guild.Name != "" && details.Guild.Name != "Нет гильдии" {
-					guildNameSlugLower := strings.ToLower(details.Guild.Name)
-					guildNameSlug := strings.ReplaceAll(guildNameSlugLower, " ", "-")
-					newGuild := entity.Guild{
-						CharacterID: details.ID,
-						GuildID:     details.Guild.ID,
-						Name:        details.Guild.Name,
-						NameSlug:    guildNameSlug,
-						Realm:       details.Guild.Realm.Name,
-						RealmSlug:   details.Guild.Realm.Slug,
-						Faction:     details.Guild.Faction.Name,
-					}
-					guilds = append(guilds, newGuild)
-				}
-				mu.Unlock()
-			}
-		}()
+		br.logFor(ctx).WithError(err).Error("failed parse user data")
+		return nil, err
 	}
 
-	go func() {
-		for _, acc := range profile.WowAccounts {
-			for _, char := range acc.Characters {
-				select {
-				case <-ctx.Done():
-					return
-				case jobs <- job{char: char}:
-				}
+	chars := make([]dto.CharacterSummary, 0)
+	for _, acc := range profile.WowAccounts {
+		chars = append(chars, acc.Characters...)
+	}
+
+	return br.fetchCharacters(ctx, blizzAccess, user, chars, conditional)
+}
+
+// GetKnownCharacters refreshes characters already stored for an account. It
+// needs no user token, so it works with a client-credentials token.
+func (br *blizzardRepository) GetKnownCharacters(ctx context.Context, blizzAccess string, known []entity.Character, conditional bool) (*entity.SyncBatch, error) {
+	ctx, span := tracer.Start(ctx, "blizzard.GetKnownCharacters", trace.WithAttributes(attribute.Bool("conditional", conditional)))
+	defer span.End()
+
+	if blizzAccess == "" {
+		br.logFor(ctx).Error("access header is missing")
+		return nil, errors.NewAppError("access token is empty", nil)
+	}
+	if len(known) == 0 {
+		return &entity.SyncBatch{
+			Characters: make([]entity.Character, 0),
+			Guilds:     make([]entity.Guild, 0),
+			Validators: make([]entity.HTTPValidator, 0),
+		}, nil
+	}
+
+	user := &dto.UserDTO{ID: known[0].BlizzardID, Battletag: known[0].Battletag}
+	chars := make([]dto.CharacterSummary, 0, len(known))
+	for _, k := range known {
+		var char dto.CharacterSummary
+		char.Name = k.Name
+		char.Realm.Name = k.Realm
+		char.Realm.Slug = k.RealmSlug
+		if char.Realm.Slug == "" {
+			char.Realm.Slug = slug.Make(k.Realm)
+		}
+		char.PlayableClass.Name = k.Class
+		char.PlayableRace.Name = k.Race
+		char.Faction.Name = k.Faction
+		chars = append(chars, char)
+	}
+
+	return br.fetchCharacters(ctx, blizzAccess, user, chars, conditional)
+}
+
+func (br *blizzardRepository) fetchCharacters(
+	ctx context.Context,
+	blizzAccess string,
+	user *dto.UserDTO,
+	chars []dto.CharacterSummary,
+	conditional bool,
+) (*entity.SyncBatch, error) {
+	span := trace.SpanFromContext(ctx)
+
+	validators := make(map[string]entity.HTTPValidator)
+	if conditional {
+		urls := make([]string, 0, len(chars)*2)
+		for _, char := range chars {
+			urls = append(urls, characterURL(char.Realm.Slug, char.Name), mythicScoreURL(char.Realm.Slug, char.Name))
+		}
+		var err error
+		validators, err = br.validators.GetHTTPValidators(ctx, urls)
+		if err != nil {
+			br.logFor(ctx).WithError(err).Warn("failed load cached validators, fetching full documents")
+			validators = make(map[string]entity.HTTPValidator)
+		}
+	}
+
+	results, err := workerpool.Map(ctx, br.pool, chars, func(ctx context.Context, char dto.CharacterSummary) fetchedCharacter {
+		return br.fetchCharacter(ctx, blizzAccess, user, char, validators)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "characters fetch interrupted")
+		br.logFor(ctx).WithError(err).Warn("characters fetch interrupted")
+		return nil, errors.NewAppError("characters fetch interrupted", err)
+	}
+
+	batch := &entity.SyncBatch{
+		Characters: make([]entity.Character, 0, len(results)),
+		Guilds:     make([]entity.Guild, 0),
+		Validators: make([]entity.HTTPValidator, 0),
+	}
+	var fetchErr error
+	for _, res := range results {
+		if res.unchanged {
+			batch.Unchanged++
+			continue
+		}
+		if res.err != nil {
+			batch.Failed++
+			if fetchErr == nil {
+				fetchErr = res.err
 			}
+			continue
+		}
+		batch.Characters = append(batch.Characters, res.char)
+		if res.guild != nil {
+			batch.Guilds = append(batch.Guilds, *res.guild)
 		}
-		close(jobs)
-	}()
+		batch.Validators = append(batch.Validators, res.validators...)
+	}
 
-	wg.Wait()
-	br.log.Info("Characters and guilds parsed succeeded")
-	return characters, guilds, nil
+	span.SetAttributes(
+		attribute.Int("characters.updated", len(batch.Characters)),
+		attribute.Int("characters.unchanged", batch.Unchanged),
+		attribute.Int("characters.failed", batch.Failed),
+	)
+
+	if batch.Failed > 0 && batch.Failed == len(results) {
+		span.RecordError(fetchErr)
+		span.SetStatus(codes.Error, "no character fetched")
+		br.logFor(ctx).WithError(fetchErr).Warn("failed to fetch any character")
+		return nil, errors.NewAppError("failed to fetch characters", fetchErr)
+	}
+
+	br.logFor(ctx).WithFields(logrus.Fields{
+		"updated":   len(batch.Characters),
+		"unchanged": batch.Unchanged,
+		"failed":    batch.Failed,
+	}).Info("Characters and guilds parsed succeeded")
+	return batch, nil
 }
 
-func (br *blizzardRepository) getCharacterDetails(ctx context.Context, blizzAccess, realm, charName string) (*dto.CharacterDetailsResponse, error) {
-	if blizzAccess == "" || realm == "" || charName == "" {
-		br.log.Error("access header/realm/character name is missing")
-		return nil, fmt.Errorf("token/realm/character name is empty")
+type fetchedCharacter struct {
+	char       entity.Character
+	guild      *entity.Guild
+	validators []entity.HTTPValidator
+	unchanged  bool
+	err        error
+}
+
+// fetchCharacter loads both documents of a character. When only one of them
+// answers 304 the other one is fetched again unconditionally, because a
+// character row is always written from both documents together.
+func (br *blizzardRepository) fetchCharacter(
+	ctx context.Context,
+	blizzAccess string,
+	user *dto.UserDTO,
+	char dto.CharacterSummary,
+	validators map[string]entity.HTTPValidator,
+) fetchedCharacter {
+	ctx, span := tracer.Start(ctx, "blizzard.fetchCharacter", trace.WithAttributes(
+		attribute.String("character", char.Name),
+		attribute.String("realm", char.Realm.Slug),
+	))
+	defer span.End()
+
+	mythValidator := lookupValidator(validators, mythicScoreURL(char.Realm.Slug, char.Name))
+	detailsValidator := lookupValidator(validators, characterURL(char.Realm.Slug, char.Name))
+
+	mythScore, mythNext, mythErr := br.getMythicScore(ctx, blizzAccess, char.Realm.Slug, char.Name, mythValidator)
+	details, detailsNext, detailsErr := br.getCharacterDetails(ctx, blizzAccess, char.Realm.Slug, char.Name, detailsValidator)
+
+	mythSame := mythErr == errNotModified
+	detailsSame := detailsErr == errNotModified
+	if mythSame && detailsSame {
+		return fetchedCharacter{unchanged: true}
+	}
+	if mythSame {
+		mythScore, mythNext, mythErr = br.getMythicScore(ctx, blizzAccess, char.Realm.Slug, char.Name, nil)
+	}
+	if detailsSame {
+		details, detailsNext, detailsErr = br.getCharacterDetails(ctx, blizzAccess, char.Realm.Slug, char.Name, nil)
+	}
+
+	// A half-fetched character would overwrite the stored row with zeroes, so
+	// it is left out of the batch and keeps its stored data.
+	if detailsErr != nil {
+		br.logFor(ctx).WithError(detailsErr).WithFields(logrus.Fields{
+			"character": char.Name,
+			"realm":     char.Realm.Slug,
+		}).Warn("failed to get character details")
+		return fetchedCharacter{err: detailsErr}
+	}
+	if mythErr != nil {
+		br.logFor(ctx).WithError(mythErr).WithFields(logrus.Fields{
+			"character": char.Name,
+			"realm":     char.Realm.Slug,
+		}).Warn("failed to get mythic score")
+		return fetchedCharacter{err: mythErr}
+	}
+
+	res := fetchedCharacter{validators: make([]entity.HTTPValidator, 0, 2)}
+	if mythNext != nil {
+		res.validators = append(res.validators, *mythNext)
+	}
+	if detailsNext != nil {
+		res.validators = append(res.validators, *detailsNext)
+	}
+
+	// The profile is the only fresh source of level for stored characters,
+	// whose summary is rebuilt from the database; the account summary's level
+	// is kept for the rare response without one.
+	level := details.Level
+	if level == 0 {
+		level = char.Level
+	}
+
+	res.char = entity.Character{
+		CharacterID: details.ID,
+		BlizzardID:  user.ID,
+		Battletag:   user.Battletag,
+		Name:        char.Name,
+		Race:        char.PlayableRace.Name,
+		Realm:       char.Realm.Name,
+		RealmSlug:   char.Realm.Slug,
+		Faction:     char.Faction.Name,
+		Class:       char.PlayableClass.Name,
+		Spec:        details.Spec.Name,
+		Lvl:         level,
+		Ilvl:        details.Ilvl,
+		Guild:       details.Guild.Name,
+		MythicScore: mythScore,
+		IsMain:      false,
+	}
+
+	if details.Guild.ID != 0 && details.Guild.Name != "" && details.Guild.Name != "Нет гильдии" {
+		res.guild = &entity.Guild{
+			CharacterID: details.ID,
+			GuildID:     details.Guild.ID,
+			Name:        details.Guild.Name,
+			NameSlug:    slug.Make(details.Guild.Name),
+			Realm:       details.Guild.Realm.Name,
+			RealmSlug:   details.Guild.Realm.Slug,
+			Faction:     details.Guild.Faction.Name,
+		}
 	}
 
-	charName = strings.ToLower(charName)
-	encodedName := url.QueryEscape(charName)
-	charURL := fmt.Sprintf("https://eu.api.blizzard.com/profile/wow/character/%s/%s?namespace=profile-eu&locale=ru_RU", realm, encodedName)
+	return res
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", charURL, nil)
-	if err != nil {
-		br.log.WithError(err).Errorf("failed create character details request for character %s: %v ", charName, err)
-		return nil, errors.NewAppError("failed create character details request", err)
+func (br *blizzardRepository) getCharacterDetails(ctx context.Context, blizzAccess, realm, charName string, validator *entity.HTTPValidator) (*dto.CharacterDetailsResponse, *entity.HTTPValidator, error) {
+	if blizzAccess == "" || realm == "" || charName == "" {
+		br.logFor(ctx).Error("access header/realm/character name is missing")
+		return nil, nil, fmt.Errorf("token/realm/character name is empty")
 	}
-	req.Header.Set("Authorization", "Bearer "+blizzAccess)
 
-	if err := br.limiter.Wait(ctx); err != nil {
-		return nil, errors.NewAppError("rate limit exeeded: %w", err)
+	charURL := characterURL(realm, charName)
+
+	req, err := newAPIRequest(ctx, blizzAccess, charURL, validator)
+	if err != nil {
+		br.logFor(ctx).WithError(err).Errorf("failed create character details request for character %s: %v ", charName, err)
+		return nil, nil, errors.NewAppError("failed create character details request", err)
 	}
 
-	resp, err := br.client.Do(req)
+	resp, err := br.apiClient.Do(req)
 	if err != nil {
-		br.log.WithError(err).WithFields(logrus.Fields{
-			"req":       req,
+		br.logFor(ctx).WithError(err).WithFields(logrus.Fields{
 			"character": charName,
 			"realm":     realm,
 		}).Error("failed get character details response by api")
-		return nil, errors.NewAppError("failed get character details response by api", err)
+		return nil, nil, errors.NewAppError("failed get character details response by api", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil, errNotModified
+	}
+
 	body, _ := io.ReadAll(resp.Body)
 	resp.Body = io.NopCloser(bytes.NewReader(body))
 
 	if resp.StatusCode != http.StatusOK {
-		br.log.WithFields(logrus.Fields{
+		br.logFor(ctx).WithFields(logrus.Fields{
 			"status":    resp.StatusCode,
 			"body":      string(body),
 			"character": charName,
 			"realm":     realm,
 			"url":       charURL,
-		}).Warn("bad request")
-		return nil, fmt.Errorf("bad request")
+		}).Warn(statusMessage(resp.StatusCode))
+		return nil, nil, apiError(resp.StatusCode)
 	}
 
 	var details dto.CharacterDetailsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&details); err != nil {
-		br.log.WithError(err).WithField("body", resp.Body).Warn("failed to decode details")
-		return nil, errors.NewAppError("failed to decode details", err)
+		br.logFor(ctx).WithError(err).WithField("body", string(body)).Warn("failed to decode details")
+		return nil, nil, errors.NewAppError("failed to decode details", err)
 	}
 
 	if details.Spec.Name == "" {
@@ -327,64 +597,306 @@ func (br *blizzardRepository) getCharacterDetails(ctx context.Context, blizzAcce
 		details.Guild.Name = "Нет гильдии"
 	}
 
-	return &details, nil
+	return &details, responseValidator(charURL, resp), nil
 }
 
-func (br *blizzardRepository) getMythicScore(ctx context.Context, blizzAccess, realm, charName string) (float64, error) {
+func (br *blizzardRepository) getMythicScore(ctx context.Context, blizzAccess, realm, charName string, validator *entity.HTTPValidator) (float64, *entity.HTTPValidator, error) {
 	if blizzAccess == "" || realm == "" || charName == "" {
-		br.log.Error("access header/realm/character name is missing")
-		return 0, fmt.Errorf("token/realm/character name is empty")
+		br.logFor(ctx).Error("access header/realm/character name is missing")
+		return 0, nil, fmt.Errorf("token/realm/character name is empty")
 	}
 
-	charName = strings.ToLower(charName)
-	encodedName := url.QueryEscape(charName)
-	mythURL := fmt.Sprintf("https://eu.api.blizzard.com/profile/wow/character/%s/%s/mythic-keystone-profile?namespace=profile-eu&locale=ru_RU", realm, encodedName)
+	mythURL := mythicScoreURL(realm, charName)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", mythURL, nil)
+	req, err := newAPIRequest(ctx, blizzAccess, mythURL, validator)
 	if err != nil {
-		br.log.WithError(err).Errorf("failed create mythic score request for character %s: %v ", charName, err)
-		return 0, errors.NewAppError("failed create mythic score request", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+blizzAccess)
-
-	if err := br.limiter.Wait(ctx); err != nil {
-		return 0, errors.NewAppError("rate limit exeeded: %w", err)
+		br.logFor(ctx).WithError(err).Errorf("failed create mythic score request for character %s: %v ", charName, err)
+		return 0, nil, errors.NewAppError("failed create mythic score request", err)
 	}
 
-	resp, err := br.client.Do(req)
+	resp, err := br.apiClient.Do(req)
 	if err != nil {
-		br.log.WithError(err).WithFields(logrus.Fields{
-			"req":       req,
+		br.logFor(ctx).WithError(err).WithFields(logrus.Fields{
 			"character": charName,
 			"realm":     realm,
 		}).Error("failed get mythic score response by api")
-		return 0, errors.NewAppError("failed get mythic score response by api", err)
+		return 0, nil, errors.NewAppError("failed get mythic score response by api", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		return 0, nil, errNotModified
+	}
+
 	body, _ := io.ReadAll(resp.Body)
 	resp.Body = io.NopCloser(bytes.NewReader(body))
 
 	if resp.StatusCode == http.StatusNotFound {
-		return 0, nil
+		return 0, nil, nil
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		br.log.WithFields(logrus.Fields{
+		br.logFor(ctx).WithFields(logrus.Fields{
 			"status":    resp.StatusCode,
 			"body":      string(body),
 			"character": charName,
 			"realm":     realm,
 			"url":       mythURL,
-		}).Warn("bad request")
-		return 0, fmt.Errorf("bad request")
+		}).Warn(statusMessage(resp.StatusCode))
+		return 0, nil, apiError(resp.StatusCode)
 	}
 
 	var mythScoreDto dto.MythScoreDto
 	if err := json.NewDecoder(resp.Body).Decode(&mythScoreDto); err != nil {
-		br.log.WithError(err).Warn("failed to decode mythic score")
-		return 0, errors.NewAppError("failed to decode mythic score", err)
+		br.logFor(ctx).WithError(err).Warn("failed to decode mythic score")
+		return 0, nil, errors.NewAppError("failed to decode mythic score", err)
+	}
+
+	return mythScoreDto.Current.Rating, responseValidator(mythURL, resp), nil
+}
+
+func (br *blizzardRepository) GetRealmIndex(ctx context.Context, blizzAccess string) ([]slug.Realm, error) {
+	if blizzAccess == "" {
+		br.logFor(ctx).Error("access header is missing")
+		return nil, errors.NewAppError("access token is empty", nil)
+	}
+
+	req, err := newAPIRequest(ctx, blizzAccess, "https://eu.api.blizzard.com/data/wow/realm/index?namespace=dynamic-eu", nil)
+	if err != nil {
+		br.logFor(ctx).WithError(err).Errorf("failed create realm index request: %v ", err)
+		return nil, errors.NewAppError("failed create realm index request", err)
+	}
+
+	resp, err := br.apiClient.Do(req)
+	if err != nil {
+		br.logFor(ctx).WithError(err).Error("failed get realm index response")
+		return nil, errors.NewAppError("failed get realm index response", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		br.logFor(ctx).WithFields(logrus.Fields{
+			"status": resp.StatusCode,
+			"body":   string(body),
+		}).Warn("bad response from API")
+		return nil, apiError(resp.StatusCode)
+	}
+
+	var index dto.RealmIndexResponse
+	if err := json.NewDecoder(resp.Body).Decode(&index); err != nil {
+		br.logFor(ctx).WithError(err).Error("failed to decode realm index response")
+		return nil, errors.NewAppError("failed to decode realm index", err)
+	}
+
+	realms := make([]slug.Realm, 0, len(index.Realms))
+	for _, r := range index.Realms {
+		realms = append(realms, slug.Realm{
+			ID:    r.ID,
+			Slug:  r.Slug,
+			Names: r.Name,
+		})
+	}
+
+	br.logFor(ctx).Infof("Realm index fetched: %d realms", len(realms))
+	return realms, nil
+}
+
+func (br *blizzardRepository) GetGuildRoster(ctx context.Context, blizzAccess, realmSlug, nameSlug string) (*entity.GuildRoster, error) {
+	if blizzAccess == "" || realmSlug == "" || nameSlug == "" {
+		br.logFor(ctx).Error("access header/realm/guild slug is missing")
+		return nil, errors.NewAppError("token/realm/guild slug is empty", nil)
+	}
+
+	rosterURL := fmt.Sprintf("https://eu.api.blizzard.com/data/wow/guild/%s/%s/roster?namespace=profile-eu&locale=ru_RU",
+		url.PathEscape(realmSlug), url.PathEscape(nameSlug))
+
+	req, err := newAPIRequest(ctx, blizzAccess, rosterURL, nil)
+	if err != nil {
+		br.logFor(ctx).WithError(err).Errorf("failed create guild roster request: %v ", err)
+		return nil, errors.NewAppError("failed create guild roster request", err)
+	}
+
+	resp, err := br.apiClient.Do(req)
+	if err != nil {
+		br.logFor(ctx).WithError(err).Error("failed get guild roster response")
+		return nil, errors.NewAppError("failed get guild roster response", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		br.logFor(ctx).WithFields(logrus.Fields{
+			"status": resp.StatusCode,
+			"body":   string(body),
+			"realm":  realmSlug,
+			"guild":  nameSlug,
+		}).Warn(statusMessage(resp.StatusCode))
+		return nil, apiError(resp.StatusCode)
+	}
+
+	var rosterResp dto.GuildRosterResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rosterResp); err != nil {
+		br.logFor(ctx).WithError(err).Error("failed to decode guild roster response")
+		return nil, errors.NewAppError("failed to decode guild roster", err)
+	}
+
+	g := rosterResp.Guild
+	roster := &entity.GuildRoster{
+		GuildID:   g.ID,
+		Name:      g.Name,
+		NameSlug:  slug.Make(g.Name),
+		Realm:     g.Realm.Name,
+		RealmSlug: g.Realm.Slug,
+		Faction:   g.Faction.Name,
+		Members:   make([]entity.GuildMember, 0, len(rosterResp.Members)),
+	}
+	for _, m := range rosterResp.Members {
+		roster.Members = append(roster.Members, entity.GuildMember{
+			GuildID:     g.ID,
+			CharacterID: m.Character.ID,
+			Name:        m.Character.Name,
+			RealmSlug:   m.Character.Realm.Slug,
+			Level:       m.Character.Level,
+			ClassID:     m.Character.PlayableClass.ID,
+			Rank:        m.Rank,
+		})
+	}
+
+	br.logFor(ctx).WithField("guild", roster.NameSlug).Infof("Guild roster fetched: %d members", len(roster.Members))
+	return roster, nil
+}
+
+// GetClientToken returns an application token from the client-credentials
+// flow, reused until shortly before it expires. It can read public profile
+// data but not an account's character list.
+func (br *blizzardRepository) GetClientToken(ctx context.Context) (string, error) {
+	br.tokenMu.Lock()
+	defer br.tokenMu.Unlock()
+
+	if br.clientToken != "" && time.Now().Before(br.clientTokenExp) {
+		return br.clientToken, nil
+	}
+
+	if br.clientID == "" || br.clientSecret == "" {
+		br.logFor(ctx).Error("blizzard client credentials are not configured")
+		return "", errors.NewAppError("blizzard client credentials are not configured", nil)
 	}
 
-	return mythScoreDto.Current.Rating, nil
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://oauth.battle.net/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", errors.NewAppError("failed create client token request", err)
+	}
+	req.SetBasicAuth(br.clientID, br.clientSecret)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := br.oauthClient.Do(req)
+	if err != nil {
+		br.logFor(ctx).WithError(err).Error("failed get client token response")
+		return "", errors.NewAppError("failed get client token response", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		br.logFor(ctx).WithField("status", resp.StatusCode).Warn("bad response from Blizzard OAuth")
+		return "", apiError(resp.StatusCode)
+	}
+
+	var tokenResp dto.ClientTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", errors.NewAppError("failed to decode client token response", err)
+	}
+
+	br.clientToken = tokenResp.AccessToken
+	br.clientTokenExp = time.Now().Add(time.Duration(tokenResp.ExpiresIn)*time.Second - time.Minute)
+	return br.clientToken, nil
+}
+
+func characterURL(realm, charName string) string {
+	return fmt.Sprintf("https://eu.api.blizzard.com/profile/wow/character/%s/%s?namespace=profile-eu&locale=ru_RU",
+		realm, url.PathEscape(strings.ToLower(charName)))
+}
+
+func mythicScoreURL(realm, charName string) string {
+	return fmt.Sprintf("https://eu.api.blizzard.com/profile/wow/character/%s/%s/mythic-keystone-profile?namespace=profile-eu&locale=ru_RU",
+		realm, url.PathEscape(strings.ToLower(charName)))
+}
+
+func newAPIRequest(ctx context.Context, blizzAccess, apiURL string, validator *entity.HTTPValidator) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+blizzAccess)
+
+	if validator != nil {
+		if validator.ETag != "" {
+			req.Header.Set("If-None-Match", validator.ETag)
+		}
+		if validator.LastModified != "" {
+			req.Header.Set("If-Modified-Since", validator.LastModified)
+		}
+	}
+
+	return req, nil
+}
+
+func responseValidator(apiURL string, resp *http.Response) *entity.HTTPValidator {
+	etag := resp.Header.Get("ETag")
+	lastModified := resp.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return nil
+	}
+	return &entity.HTTPValidator{URL: apiURL, ETag: etag, LastModified: lastModified}
+}
+
+func lookupValidator(validators map[string]entity.HTTPValidator, apiURL string) *entity.HTTPValidator {
+	if v, ok := validators[apiURL]; ok {
+		return &v
+	}
+	return nil
+}
+
+func blizzardEndpoint(req *http.Request) string {
+	path := req.URL.Path
+	switch {
+	case path == "/profile/user/wow":
+		return "account_profile"
+	case strings.HasPrefix(path, "/profile/wow/character/") && strings.HasSuffix(path, "/mythic-keystone-profile"):
+		return "character_mythic_keystone"
+	case strings.HasPrefix(path, "/profile/wow/character/"):
+		return "character"
+	case strings.HasPrefix(path, "/data/wow/realm/"):
+		return "realm_index"
+	case strings.HasPrefix(path, "/data/wow/guild/") && strings.HasSuffix(path, "/roster"):
+		return "guild_roster"
+	case path == "/token":
+		return "oauth_token"
+	default:
+		return "other"
+	}
+}
+
+func authEndpoint(req *http.Request) string {
+	return req.URL.Path
+}
+
+func apiError(status int) error {
+	return errors.NewHTTPError(status, statusMessage(status), nil)
+}
+
+func statusMessage(status int) string {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return "rate limited by API"
+	case status >= http.StatusInternalServerError:
+		return "API unavailable"
+	default:
+		return "bad request"
+	}
+}
+
+func (br *blizzardRepository) logFor(ctx context.Context) *logrus.Entry {
+	return logger.FromContext(ctx, br.log)
 }
@@ -0,0 +1,301 @@
+package blizzard
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	headerQuotaAllotted = "X-Plan-Quota-Allotted"
+	headerQuotaCurrent  = "X-Plan-Quota-Current"
+	headerQpsAllotted   = "X-Plan-Qps-Allotted"
+	defaultRetryAfter   = time.Second
+	minPerSecond        = 1
+	attemptTimeout      = 10 * time.Second
+)
+
+type Budget struct {
+	PerSecondLimit  float64   `json:"per_second_limit"`
+	PerSecondRate   float64   `json:"per_second_rate"`
+	HourlyLimit     int       `json:"hourly_limit"`
+	HourlyUsed      int       `json:"hourly_used"`
+	HourlyRemaining int       `json:"hourly_remaining"`
+	HourlyResetAt   time.Time `json:"hourly_reset_at"`
+	PausedUntil     time.Time `json:"paused_until,omitempty"`
+}
+
+// rateLimitTransport throttles every Blizzard API call with a per-second and an
+// hourly token bucket. A 429 halves the per-second rate and pauses all calls
+// until Retry-After; each successful call then wins back one request/second.
+// Each attempt gets its own timeout, so waiting for the limiter never counts
+// against it.
+type rateLimitTransport struct {
+	base       http.RoundTripper
+	log        *logrus.Logger
+	maxRetries int
+	onWait     func(time.Duration)
+	timeout    time.Duration
+
+	mu          sync.Mutex
+	maxRate     rate.Limit
+	second      *rate.Limiter
+	hourly      *rate.Limiter
+	hourlyLimit int
+	hourlyUsed  int
+	quotaSeen   int
+	windowStart time.Time
+	pausedUntil time.Time
+}
+
+func newRateLimitTransport(base http.RoundTripper, perSecond, burst, perHour, maxRetries int, onWait func(time.Duration), log *logrus.Logger) *rateLimitTransport {
+	hourlyBurst := perHour / 60
+	if hourlyBurst < 1 {
+		hourlyBurst = 1
+	}
+
+	return &rateLimitTransport{
+		base:        base,
+		log:         log,
+		maxRetries:  maxRetries,
+		onWait:      onWait,
+		timeout:     attemptTimeout,
+		maxRate:     rate.Limit(perSecond),
+		second:      rate.NewLimiter(rate.Limit(perSecond), burst),
+		hourly:      rate.NewLimiter(rate.Every(time.Hour/time.Duration(perHour)), hourlyBurst),
+		hourlyLimit: perHour,
+		windowStart: time.Now(),
+	}
+}
+
+// SetLimits applies new configured limits. A rate currently lowered after a
+// 429 stays lowered and recovers towards the new ceiling.
+func (t *rateLimitTransport) SetLimits(perSecond, burst, perHour, maxRetries int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.maxRetries = maxRetries
+	t.maxRate = rate.Limit(perSecond)
+	if t.second.Limit() > t.maxRate {
+		t.second.SetLimit(t.maxRate)
+	}
+	t.second.SetBurst(burst)
+
+	hourlyBurst := perHour / 60
+	if hourlyBurst < 1 {
+		hourlyBurst = 1
+	}
+	t.hourly.SetLimit(rate.Every(time.Hour / time.Duration(perHour)))
+	t.hourly.SetBurst(hourlyBurst)
+	t.hourlyLimit = perHour
+}
+
+func (t *rateLimitTransport) retries() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.maxRetries
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	maxRetries := t.retries()
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.send(req)
+		if err != nil {
+			return nil, err
+		}
+
+		t.observe(resp)
+
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return resp, nil
+		}
+
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		t.backOff(retryAfter)
+
+		if attempt >= maxRetries || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// send runs one attempt under its own deadline. The deadline stays attached
+// to the body, so it is released when the caller closes it.
+func (t *rateLimitTransport) send(req *http.Request) (*http.Response, error) {
+	if t.timeout <= 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func (t *rateLimitTransport) wait(req *http.Request) error {
+	ctx := req.Context()
+	if t.onWait != nil {
+		start := time.Now()
+		defer func() { t.onWait(time.Since(start)) }()
+	}
+
+	t.mu.Lock()
+	pause := time.Until(t.pausedUntil)
+	t.mu.Unlock()
+
+	if pause > 0 {
+		timer := time.NewTimer(pause)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if err := t.hourly.Wait(ctx); err != nil {
+		return err
+	}
+	if err := t.second.Wait(ctx); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	if time.Since(t.windowStart) >= time.Hour {
+		t.windowStart = time.Now()
+		t.hourlyUsed = 0
+	}
+	t.hourlyUsed++
+	t.mu.Unlock()
+
+	return nil
+}
+
+func (t *rateLimitTransport) observe(resp *http.Response) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if allotted, err := strconv.Atoi(resp.Header.Get(headerQuotaAllotted)); err == nil && allotted > 0 {
+		t.hourlyLimit = allotted
+	}
+	if current, err := strconv.Atoi(resp.Header.Get(headerQuotaCurrent)); err == nil {
+		// Blizzard's quota window resets on its own clock. A sharp drop in the
+		// reported usage marks the reset; small drops are just responses
+		// arriving out of order.
+		if current < t.quotaSeen/2 {
+			t.windowStart = time.Now()
+			t.log.WithField("quota_current", current).Debug("blizzard hourly quota window reset")
+		}
+		t.quotaSeen = current
+		t.hourlyUsed = current
+		if current >= t.hourlyLimit {
+			t.pausedUntil = t.windowStart.Add(time.Hour)
+			t.log.WithField("resume_at", t.pausedUntil).Warn("blizzard hourly quota exhausted")
+		}
+	}
+	if qps, err := strconv.Atoi(resp.Header.Get(headerQpsAllotted)); err == nil && qps > 0 && rate.Limit(qps) < t.maxRate {
+		t.maxRate = rate.Limit(qps)
+		if t.second.Limit() > t.maxRate {
+			t.second.SetLimit(t.maxRate)
+		}
+	}
+
+	if resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusTooManyRequests {
+		if current := t.second.Limit(); current < t.maxRate {
+			t.second.SetLimit(min(current+1, t.maxRate))
+		}
+	}
+}
+
+func (t *rateLimitTransport) backOff(retryAfter time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	next := t.second.Limit() / 2
+	if next < minPerSecond {
+		next = minPerSecond
+	}
+	t.second.SetLimit(next)
+
+	if until := time.Now().Add(retryAfter); until.After(t.pausedUntil) {
+		t.pausedUntil = until
+	}
+
+	t.log.WithFields(logrus.Fields{
+		"retry_after": retryAfter,
+		"per_second":  float64(next),
+	}).Warn("blizzard rate limit hit, backing off")
+}
+
+func (t *rateLimitTransport) Budget() Budget {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	remaining := t.hourlyLimit - t.hourlyUsed
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	budget := Budget{
+		PerSecondLimit:  float64(t.maxRate),
+		PerSecondRate:   float64(t.second.Limit()),
+		HourlyLimit:     t.hourlyLimit,
+		HourlyUsed:      t.hourlyUsed,
+		HourlyRemaining: remaining,
+		HourlyResetAt:   t.windowStart.Add(time.Hour),
+	}
+	if t.pausedUntil.After(time.Now()) {
+		budget.PausedUntil = t.pausedUntil
+	}
+	return budget
+}
+
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return defaultRetryAfter
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return defaultRetryAfter
+}
@@ -0,0 +1,96 @@
+package blizzard
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func testResponse(status int, header http.Header) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(strings.NewReader("{}"))}
+}
+
+func newTestTransport(base http.RoundTripper, maxRetries int) *rateLimitTransport {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return newRateLimitTransport(base, 100, 10, 36000, maxRetries, nil, log)
+}
+
+func TestRateLimitTransportRetryAfterDoesNotCountAgainstAttemptTimeout(t *testing.T) {
+	calls := 0
+	tr := newTestTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return testResponse(http.StatusTooManyRequests, http.Header{"Retry-After": {"1"}}), nil
+		}
+		return testResponse(http.StatusOK, nil), nil
+	}), 1)
+	tr.timeout = 300 * time.Millisecond
+
+	req, _ := http.NewRequest(http.MethodGet, "https://eu.api.blizzard.com/profile", nil)
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls != 2 {
+		t.Errorf("got status %d after %d calls, want 200 after the Retry-After pause", resp.StatusCode, calls)
+	}
+}
+
+func TestRateLimitTransportAttemptTimeout(t *testing.T) {
+	tr := newTestTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}), 0)
+	tr.timeout = 50 * time.Millisecond
+
+	req, _ := http.NewRequest(http.MethodGet, "https://eu.api.blizzard.com/profile", nil)
+	if _, err := tr.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RoundTrip error = %v, want deadline exceeded", err)
+	}
+}
+
+func TestRateLimitTransportRealignsWindowOnQuotaDrop(t *testing.T) {
+	tr := newTestTransport(nil, 0)
+	tr.windowStart = time.Now().Add(-50 * time.Minute)
+
+	quota := func(current int) *http.Response {
+		return testResponse(http.StatusOK, http.Header{
+			headerQuotaAllotted: {"36000"},
+			headerQuotaCurrent:  {strconv.Itoa(current)},
+		})
+	}
+
+	tr.observe(quota(3000))
+	tr.observe(quota(2999))
+	if reset := tr.Budget().HourlyResetAt; time.Until(reset) > 15*time.Minute {
+		t.Fatalf("out-of-order response moved the reset to %s", reset)
+	}
+
+	tr.observe(quota(4))
+	budget := tr.Budget()
+	if time.Until(budget.HourlyResetAt) < 55*time.Minute {
+		t.Errorf("reset at %s, want about an hour after the observed drop", budget.HourlyResetAt)
+	}
+	if budget.HourlyUsed != 4 {
+		t.Errorf("hourly used = %d, want 4", budget.HourlyUsed)
+	}
+}
@@ -2,15 +2,62 @@ package database
 
 import (
 	"context"
+	"errors"
 	"profile-service/internal/entity"
+	"profile-service/pkg/slug"
+	"time"
+)
+
+var (
+	// ErrCharacterNotFound is returned when no character of the account matches.
+	ErrCharacterNotFound = errors.New("character not found")
+	// ErrCharacterAmbiguous is returned when a name without a realm matches
+	// characters of the account on more than one realm.
+	ErrCharacterAmbiguous = errors.New("character name matches several realms")
 )
 
 type PostgresRepository interface {
+	WithTx(ctx context.Context, fn func(repo PostgresRepository) error) error
 	GetCharacters(ctx context.Context, blizzardID string) ([]entity.Character, error)
 	SaveCharacters(ctx context.Context, characters []entity.Character) error
-	GetCharacterByName(ctx context.Context, charName string) (*entity.Character, error)
-	SetMainCharacter(ctx context.Context, blizzardID, charName string) error
+	GetCharacterByName(ctx context.Context, blizzardID, realmSlug, charName string) (*entity.Character, error)
+	SetMainCharacter(ctx context.Context, blizzardID, realmSlug, charName string) error
+	SavePreference(ctx context.Context, blizzardID, realmSlug, charName string, pref entity.Preference) error
 	SaveGuilds(ctx context.Context, guilds []entity.Guild) error
-	GetGuildByName(ctx context.Context, nameSlug string) (*entity.Guild, error)
+	SaveCharacterSnapshots(ctx context.Context, snapshots []entity.CharacterSnapshot) error
+	GetGuildByName(ctx context.Context, nameSlug, realmSlug string) (*entity.Guild, error)
+	GetCharacterGuilds(ctx context.Context, characterIDs []int) ([]entity.Guild, error)
+	DeleteCharacterGuilds(ctx context.Context, characterIDs []int) error
 	GetMainCharacterByBlizzardID(ctx context.Context, blizzardID string) (*entity.Character, error)
+	GetMains(ctx context.Context, blizzardIDs, battletags []string) ([]entity.Character, error)
+	GetAccountSummary(ctx context.Context, blizzardID string, maxLevel int) (*entity.AccountSummary, error)
+	GetGuildLeaderboard(ctx context.Context, filter entity.LeaderboardFilter) (*entity.LeaderboardPage, error)
+	Search(ctx context.Context, filter entity.SearchFilter) (*entity.SearchResult, error)
+	SaveRealms(ctx context.Context, realms []slug.Realm) error
+	GetRealms(ctx context.Context) ([]slug.Realm, error)
+	SaveSyncState(ctx context.Context, blizzardID string, characters int) error
+	GetSyncState(ctx context.Context, blizzardID string) (*entity.SyncState, error)
+	SaveHTTPValidators(ctx context.Context, validators []entity.HTTPValidator) error
+	GetHTTPValidators(ctx context.Context, urls []string) (map[string]entity.HTTPValidator, error)
+	ReplaceGuildMembers(ctx context.Context, guildID int, members []entity.GuildMember) error
+	GetGuildMembers(ctx context.Context, guildID int) ([]entity.GuildMember, error)
+	SaveGuildEvents(ctx context.Context, events []entity.GuildEvent) error
+	GetGuildEvents(ctx context.Context, filter entity.GuildEventFilter) (*entity.GuildEventPage, error)
+	IsGuildMember(ctx context.Context, blizzardID string, guildID int) (bool, error)
+	CreateWebhookSubscription(ctx context.Context, sub *entity.WebhookSubscription) error
+	GetWebhookSubscriptions(ctx context.Context, blizzardID string) ([]entity.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, blizzardID string, id int64) error
+	SaveOutboxEvents(ctx context.Context, events []entity.OutboxEvent) error
+	FanOutOutbox(ctx context.Context, limit int) (int64, error)
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error)
+	FinishWebhookDelivery(ctx context.Context, d entity.WebhookDelivery) error
+	GetWebhookDeliveries(ctx context.Context, blizzardID string, subscriptionID int64, limit int) ([]entity.WebhookDelivery, error)
+	PruneWebhooks(ctx context.Context, before time.Time) error
+	SaveDomainEvents(ctx context.Context, events []entity.DomainEvent) error
+	GetUnpublishedDomainEvents(ctx context.Context, limit int) ([]entity.DomainEvent, error)
+	MarkDomainEventsPublished(ctx context.Context, ids []string) error
+	PruneDomainEvents(ctx context.Context, before time.Time) error
+	ExportCharacters(ctx context.Context, blizzardID string, fn func(entity.Character) error) error
+	Ping(ctx context.Context) error
+	MigrationVersion(ctx context.Context) (int64, bool, error)
 }
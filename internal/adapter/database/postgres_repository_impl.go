@@ -2,34 +2,98 @@ package database
 
 import (
 	"context"
+	"encoding/json"
 	"profile-service/internal/entity"
+	dbpool "profile-service/pkg/db"
 	"profile-service/pkg/errors"
+	logger "profile-service/pkg/log"
+	"profile-service/pkg/slug"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
 )
 
 var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// ilikeEqual matches column case-insensitively against value taken literally,
+// so wildcards in user input do not widen the match.
+func ilikeEqual(column, value string) sq.ILike {
+	return sq.ILike{column: likeEscaper.Replace(value)}
+}
+
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 type postgresRepository struct {
-	pool *pgxpool.Pool
-	log  *logrus.Logger
+	pool    *pgxpool.Pool
+	replica *dbpool.Replica
+	db      querier
+	inTx    bool
+	log     *logrus.Logger
 }
 
-func NewPostgresRepository(pool *pgxpool.Pool, log *logrus.Logger) *postgresRepository {
-	return &postgresRepository{pool: pool, log: log}
+// NewPostgresRepository builds the repository on the primary pool. replica may
+// be nil; when set, read-only queries go to it while it is healthy.
+func NewPostgresRepository(pool *pgxpool.Pool, replica *dbpool.Replica, log *logrus.Logger) *postgresRepository {
+	return &postgresRepository{pool: pool, replica: replica, db: pool, log: log}
 }
 
-func (pr *postgresRepository) SaveCharacters(ctx context.Context, characters []entity.Character) error {
+// reader picks the connection for read-only queries. Inside a transaction it
+// stays on the transaction so reads see its own writes, and a ctx marked with
+// dbpool.WithPrimary stays on the primary for read-your-writes.
+func (pr *postgresRepository) reader(ctx context.Context) querier {
+	if pr.inTx || dbpool.UsePrimary(ctx) {
+		return pr.db
+	}
+	if replica := pr.replica.Pool(); replica != nil {
+		return replica
+	}
+	return pr.db
+}
+
+// WithTx runs fn against a repository bound to a single transaction. Nested
+// calls reuse the outer transaction, so fn commits or rolls back as a whole.
+func (pr *postgresRepository) WithTx(ctx context.Context, fn func(repo PostgresRepository) error) error {
+	if pr.inTx {
+		return fn(pr)
+	}
+
 	tx, err := pr.pool.Begin(ctx)
 	if err != nil {
-		pr.log.WithError(err).Error("failed to begin transaction")
+		pr.logFor(ctx).WithError(err).Error("failed to begin transaction")
 		return errors.NewAppError("failed to begin transaction", err)
 	}
 	defer tx.Rollback(ctx)
 
+	txRepo := &postgresRepository{pool: pr.pool, replica: pr.replica, db: tx, inTx: true, log: pr.log}
+	if err := fn(txRepo); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to commit transaction")
+		return errors.NewAppError("failed to commit transaction", err)
+	}
+
+	return nil
+}
+
+func (pr *postgresRepository) SaveCharacters(ctx context.Context, characters []entity.Character) error {
+	if len(characters) == 0 {
+		return nil
+	}
+
 	query := psql.
 		Insert("profile").
 		Columns(
@@ -38,6 +102,7 @@ func (pr *postgresRepository) SaveCharacters(ctx context.Context, characters []e
 			"battletag",
 			"name",
 			"realm",
+			"realm_slug",
 			"race",
 			"faction",
 			"class",
@@ -46,7 +111,6 @@ func (pr *postgresRepository) SaveCharacters(ctx context.Context, characters []e
 			"ilvl",
 			"guild",
 			"mythic_score",
-			"is_main",
 		)
 
 	for _, char := range characters {
@@ -56,6 +120,7 @@ func (pr *postgresRepository) SaveCharacters(ctx context.Context, characters []e
 			char.Battletag,
 			char.Name,
 			char.Realm,
+			char.RealmSlug,
 			char.Race,
 			char.Faction,
 			char.Class,
@@ -64,13 +129,13 @@ func (pr *postgresRepository) SaveCharacters(ctx context.Context, characters []e
 			char.Ilvl,
 			char.Guild,
 			char.MythicScore,
-			char.IsMain,
 		)
 	}
 
 	sql, args, err := query.Suffix("ON CONFLICT (blizzard_id, name, realm) DO UPDATE SET " +
 		"character_id = EXCLUDED.character_id, " +
 		"battletag = EXCLUDED.battletag, " +
+		"realm_slug = EXCLUDED.realm_slug, " +
 		"race = EXCLUDED.race, " +
 		"faction = EXCLUDED.faction, " +
 		"class = EXCLUDED.class, " +
@@ -78,27 +143,21 @@ func (pr *postgresRepository) SaveCharacters(ctx context.Context, characters []e
 		"lvl = EXCLUDED.lvl, " +
 		"ilvl = EXCLUDED.ilvl, " +
 		"guild = EXCLUDED.guild, " +
-		"mythic_score = EXCLUDED.mythic_score, " +
-		"is_main = EXCLUDED.is_main").
+		"mythic_score = EXCLUDED.mythic_score").
 		ToSql()
 
 	if err != nil {
-		pr.log.WithError(err).Error("failed build query for save characters")
+		pr.logFor(ctx).WithError(err).Error("failed build query for save characters")
 		return errors.NewAppError("failed build query for save characters", err)
 	}
 
-	_, err = pr.pool.Exec(ctx, sql, args...)
+	_, err = pr.db.Exec(ctx, sql, args...)
 	if err != nil {
-		pr.log.WithError(err).Error("failed execute SQL save characters")
+		pr.logFor(ctx).WithError(err).Error("failed execute SQL save characters")
 		return errors.NewAppError("failed execute SQL save characters", err)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		pr.log.WithError(err).Error("failed to commit transaction")
-		return errors.NewAppError("failed to commit transaction", err)
-	}
-
-	pr.log.Infof("Saved/updated %d characters", len(characters))
+	pr.logFor(ctx).Infof("Saved/updated %d characters", len(characters))
 	return nil
 }
 
@@ -142,21 +201,52 @@ func (pr *postgresRepository) SaveGuilds(ctx context.Context, guilds []entity.Gu
 	`).ToSql()
 
 	if err != nil {
-		pr.log.WithError(err).Error("failed build query for save guilds")
+		pr.logFor(ctx).WithError(err).Error("failed build query for save guilds")
 		return errors.NewAppError("failed build query for save guilds", err)
 	}
 
-	_, err = pr.pool.Exec(ctx, sql, args...)
+	_, err = pr.db.Exec(ctx, sql, args...)
 	if err != nil {
-		pr.log.WithError(err).Error("failed execute SQL save guilds")
+		pr.logFor(ctx).WithError(err).Error("failed execute SQL save guilds")
 		return errors.NewAppError("failed execute SQL save guilds", err)
 	}
 
-	pr.log.Infof("Saved/updated %d guilds", len(guilds))
+	pr.logFor(ctx).Infof("Saved/updated %d guilds", len(guilds))
+	return nil
+}
+
+func (pr *postgresRepository) SaveCharacterSnapshots(ctx context.Context, snapshots []entity.CharacterSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	builder := psql.
+		Insert("character_snapshot").
+		Columns("character_id", "blizzard_id", "lvl", "ilvl", "mythic_score")
+	for _, s := range snapshots {
+		builder = builder.Values(s.CharacterID, s.BlizzardID, s.Lvl, s.Ilvl, s.MythicScore)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for save character snapshots")
+		return errors.NewAppError("failed build query for save character snapshots", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, query, args...); err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to save character snapshots")
+		return errors.NewAppError("failed to save character snapshots", err)
+	}
+
 	return nil
 }
 
-func (pr *postgresRepository) GetGuildByName(ctx context.Context, nameSlug string) (*entity.Guild, error) {
+func (pr *postgresRepository) GetGuildByName(ctx context.Context, nameSlug, realmSlug string) (*entity.Guild, error) {
+	where := sq.Eq{"name_slug": nameSlug}
+	if realmSlug != "" {
+		where["realm_slug"] = realmSlug
+	}
+
 	query, args, err := psql.Select(
 		"character_id",
 		"guild_id",
@@ -167,15 +257,16 @@ func (pr *postgresRepository) GetGuildByName(ctx context.Context, nameSlug strin
 		"faction",
 	).
 		From("guild").
-		Where(sq.Eq{"name_slug": nameSlug}).
+		Where(where).
+		Limit(1).
 		ToSql()
 	if err != nil {
-		pr.log.WithError(err).Error("failed build query for get guild")
+		pr.logFor(ctx).WithError(err).Error("failed build query for get guild")
 		return nil, errors.NewAppError("failed build query for get guild", err)
 	}
 
 	var g entity.Guild
-	err = pr.pool.QueryRow(ctx, query, args...).
+	err = pr.reader(ctx).QueryRow(ctx, query, args...).
 		Scan(
 			&g.CharacterID,
 			&g.GuildID,
@@ -188,106 +279,174 @@ func (pr *postgresRepository) GetGuildByName(ctx context.Context, nameSlug strin
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			pr.log.WithField("name_slug", nameSlug).Info("guild not found")
+			pr.logFor(ctx).WithField("name_slug", nameSlug).Info("guild not found")
 			return nil, errors.NewAppError("guild not found", err)
 		}
-		pr.log.WithError(err).Error("failed to scan guild row")
+		pr.logFor(ctx).WithError(err).Error("failed to scan guild row")
 		return nil, errors.NewAppError("failed to scan guild row", err)
 	}
 
-	pr.log.Infof("Guild %s get succeeded", nameSlug)
+	pr.logFor(ctx).Infof("Guild %s get succeeded", nameSlug)
 	return &g, nil
 }
 
-func (pr *postgresRepository) GetMainCharacterByBlizzardID(ctx context.Context, blizzardID string) (*entity.Character, error) {
+// GetCharacterGuilds returns the stored guild of each of characterIDs that has
+// one, as last saved by a sync.
+func (pr *postgresRepository) GetCharacterGuilds(ctx context.Context, characterIDs []int) ([]entity.Guild, error) {
+	if len(characterIDs) == 0 {
+		return make([]entity.Guild, 0), nil
+	}
+
 	query, args, err := psql.Select(
 		"character_id",
-		"blizzard_id",
-		"battletag",
+		"guild_id",
 		"name",
+		"name_slug",
 		"realm",
-		"race",
+		"realm_slug",
 		"faction",
-		"class",
-		"spec",
-		"lvl",
-		"ilvl",
-		"guild",
-		"mythic_score",
-		"is_main",
 	).
-		From("profile").
+		From("guild").
+		Where(sq.Eq{"character_id": characterIDs}).
+		OrderBy("character_id").
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for get character guilds")
+		return nil, errors.NewAppError("failed build query for get character guilds", err)
+	}
+
+	rows, err := pr.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed execute SQL get character guilds")
+		return nil, errors.NewAppError("failed execute SQL get character guilds", err)
+	}
+	defer rows.Close()
+
+	guilds := make([]entity.Guild, 0, len(characterIDs))
+	for rows.Next() {
+		var g entity.Guild
+		if err := rows.Scan(&g.CharacterID, &g.GuildID, &g.Name, &g.NameSlug, &g.Realm, &g.RealmSlug, &g.Faction); err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan guild row")
+			return nil, errors.NewAppError("failed to scan guild row", err)
+		}
+		guilds = append(guilds, g)
+	}
+
+	if err := rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows iteration error")
+		return nil, errors.NewAppError("rows iteration error", err)
+	}
+
+	return guilds, nil
+}
+
+// DeleteCharacterGuilds removes the guild rows of characters that left their
+// guild; SaveGuilds only upserts the current ones.
+func (pr *postgresRepository) DeleteCharacterGuilds(ctx context.Context, characterIDs []int) error {
+	if len(characterIDs) == 0 {
+		return nil
+	}
+
+	query, args, err := psql.
+		Delete("guild").
+		Where(sq.Eq{"character_id": characterIDs}).
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for delete character guilds")
+		return errors.NewAppError("failed build query for delete character guilds", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, query, args...); err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to delete character guilds")
+		return errors.NewAppError("failed to delete character guilds", err)
+	}
+
+	return nil
+}
+
+func (pr *postgresRepository) GetMainCharacterByBlizzardID(ctx context.Context, blizzardID string) (*entity.Character, error) {
+	query, args, err := selectCharacters().
 		Where(sq.Eq{
-			"blizzard_id": blizzardID,
-			"is_main":     true,
+			"p.blizzard_id": blizzardID,
+			"pp.is_main":    true,
 		}).
 		ToSql()
 	if err != nil {
-		pr.log.WithError(err).Error("failed build query for get character")
+		pr.logFor(ctx).WithError(err).Error("failed build query for get character")
 		return nil, errors.NewAppError("failed build query for get character", err)
 	}
 
-	var char entity.Character
-	err = pr.pool.QueryRow(ctx, query, args...).
-		Scan(
-			&char.CharacterID,
-			&char.BlizzardID,
-			&char.Battletag,
-			&char.Name,
-			&char.Realm,
-			&char.Race,
-			&char.Faction,
-			&char.Class,
-			&char.Spec,
-			&char.Lvl,
-			&char.Ilvl,
-			&char.Guild,
-			&char.MythicScore,
-			&char.IsMain,
-		)
+	char, err := scanCharacter(pr.reader(ctx).QueryRow(ctx, query, args...))
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			pr.log.WithField("name", char.Name).Info("character not found")
-			return nil, errors.NewAppError("character not found", err)
+			pr.logFor(ctx).WithField("blizzard_id", blizzardID).Info("main character not found")
+			return nil, ErrCharacterNotFound
 		}
-		pr.log.WithError(err).Error("failed to scan character row")
+		pr.logFor(ctx).WithError(err).Error("failed to scan character row")
 		return nil, errors.NewAppError("failed to scan character row", err)
 	}
 
-	pr.log.Infof("Character %s get succeeded", char.Name)
+	pr.logFor(ctx).Infof("Character %s get succeeded", char.Name)
 	return &char, nil
 }
 
+// GetMains returns one character per account matched by Blizzard ID or by
+// lowercased battletag: the main if set, otherwise the visible character with
+// the highest M+ score.
+func (pr *postgresRepository) GetMains(ctx context.Context, blizzardIDs, battletags []string) ([]entity.Character, error) {
+	query, args, err := selectCharacters().
+		Options("DISTINCT ON (p.blizzard_id)").
+		Where(sq.Or{
+			sq.Expr("p.blizzard_id = ANY(?)", blizzardIDs),
+			sq.Expr("lower(p.battletag) = ANY(?)", battletags),
+		}).
+		Where("(COALESCE(pp.is_main, false) OR NOT COALESCE(pp.hidden, false))").
+		OrderBy("p.blizzard_id", "COALESCE(pp.is_main, false) DESC", "p.mythic_score DESC", "p.character_id").
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for get mains")
+		return nil, errors.NewAppError("failed build query for get mains", err)
+	}
+
+	rows, err := pr.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed execute SQL get mains")
+		return nil, errors.NewAppError("failed execute SQL get mains", err)
+	}
+	defer rows.Close()
+
+	characters := make([]entity.Character, 0, len(blizzardIDs)+len(battletags))
+	for rows.Next() {
+		char, err := scanCharacter(rows)
+		if err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan character rows")
+			return nil, errors.NewAppError("failed to scan character rows", err)
+		}
+		characters = append(characters, char)
+	}
+
+	if err = rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows error")
+		return nil, errors.NewAppError("rows error", err)
+	}
+
+	return characters, nil
+}
+
 func (pr *postgresRepository) GetCharacters(ctx context.Context, blizzardID string) ([]entity.Character, error) {
-	query := psql.Select(
-		"character_id",
-		"blizzard_id",
-		"battletag",
-		"name",
-		"realm",
-		"race",
-		"faction",
-		"class",
-		"spec",
-		"lvl",
-		"ilvl",
-		"guild",
-		"mythic_score",
-		"is_main",
-	).
-		From("profile").
-		Where(sq.Eq{"blizzard_id": blizzardID}).
-		OrderBy("mythic_score DESC")
+	query := selectCharacters().
+		Where(sq.Eq{"p.blizzard_id": blizzardID}).
+		OrderBy("pp.display_order NULLS LAST", "p.mythic_score DESC")
 
 	sql, args, err := query.ToSql()
 	if err != nil {
-		pr.log.WithError(err).Error("failed build query for get characters")
+		pr.logFor(ctx).WithError(err).Error("failed build query for get characters")
 		return nil, errors.NewAppError("failed build query for get characters", err)
 	}
 
-	rows, err := pr.pool.Query(ctx, sql, args...)
+	rows, err := pr.reader(ctx).Query(ctx, sql, args...)
 	if err != nil {
-		pr.log.WithError(err).Error("failed execute SQL get characters")
+		pr.logFor(ctx).WithError(err).Error("failed execute SQL get characters")
 		return nil, errors.NewAppError("failed execute SQL get characters", err)
 	}
 	defer rows.Close()
@@ -295,85 +454,75 @@ func (pr *postgresRepository) GetCharacters(ctx context.Context, blizzardID stri
 	var characters []entity.Character
 
 	for rows.Next() {
-		char := entity.Character{}
-		err := rows.Scan(
-			&char.CharacterID,
-			&char.BlizzardID,
-			&char.Battletag,
-			&char.Name,
-			&char.Realm,
-			&char.Race,
-			&char.Faction,
-			&char.Class,
-			&char.Spec,
-			&char.Lvl,
-			&char.Ilvl,
-			&char.Guild,
-			&char.MythicScore,
-			&char.IsMain,
-		)
+		char, err := scanCharacter(rows)
 		if err != nil {
-			pr.log.WithError(err).Error("failed to scan character rows")
+			pr.logFor(ctx).WithError(err).Error("failed to scan character rows")
 			return nil, errors.NewAppError("failed to scan character rows", err)
 		}
 		characters = append(characters, char)
 	}
 
 	if err = rows.Err(); err != nil {
-		pr.log.WithError(err).Error("rows error")
+		pr.logFor(ctx).WithError(err).Error("rows error")
 		return nil, errors.NewAppError("rows error", err)
 	}
 
-	pr.log.Infof("Got %d characters for %s", len(characters), blizzardID)
+	pr.logFor(ctx).Infof("Got %d characters for %s", len(characters), blizzardID)
 	return characters, nil
 }
 
-func (pr *postgresRepository) SetMainCharacter(ctx context.Context, blizzardID, charName string) error {
-	tx, err := pr.pool.Begin(ctx)
+func (pr *postgresRepository) SetMainCharacter(ctx context.Context, blizzardID, realmSlug, charName string) error {
+	characterID, err := pr.characterIDByName(ctx, blizzardID, realmSlug, charName)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pr.db.Begin(ctx)
 	if err != nil {
-		pr.log.WithError(err).Error("failed to begin transaction")
+		pr.logFor(ctx).WithError(err).Error("failed to begin transaction")
 		return errors.NewAppError("failed to begin transaction", err)
 	}
 	defer tx.Rollback(ctx)
 
 	resetQuery, resetArgs, err := psql.
-		Update("profile").
+		Update("profile_preference").
 		Set("is_main", false).
-		Where(sq.Eq{"blizzard_id": blizzardID}).
+		Where(sq.Eq{"blizzard_id": blizzardID, "is_main": true}).
 		ToSql()
 	if err != nil {
-		pr.log.WithError(err).Error("failed build query for reset main character")
+		pr.logFor(ctx).WithError(err).Error("failed build query for reset main character")
 		return errors.NewAppError("failed build query for reset main character", err)
 	}
 
 	_, err = tx.Exec(ctx, resetQuery, resetArgs...)
 	if err != nil {
-		pr.log.WithError(err).Errorf("failed to reset main charcter for user: %s", blizzardID)
+		pr.logFor(ctx).WithError(err).Errorf("failed to reset main charcter for user: %s", blizzardID)
 		return errors.NewAppError("failed to reset main charcter", err)
 	}
 
 	setQuery, setArgs, err := psql.
-		Update("profile").
-		Set("is_main", true).
-		Where(sq.ILike{"name": charName}).
+		Insert("profile_preference").
+		Columns("character_id", "blizzard_id", "is_main").
+		Values(characterID, blizzardID, true).
+		Suffix("ON CONFLICT (character_id) DO UPDATE SET is_main = true").
 		ToSql()
 	if err != nil {
-		pr.log.WithError(err).Error("failed build query for set main character")
+		pr.logFor(ctx).WithError(err).Error("failed build query for set main character")
 		return errors.NewAppError("failed build query for set main character", err)
 	}
 
 	_, err = tx.Exec(ctx, setQuery, setArgs...)
 	if err != nil {
-		pr.log.WithError(err).Errorf("failed to set main charcter for user: %s", blizzardID)
+		pr.logFor(ctx).WithError(err).Errorf("failed to set main charcter for user: %s", blizzardID)
 		return errors.NewAppError("failed to set main charcter", err)
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		pr.log.WithError(err).Error("failed to commit set main transaction")
+		pr.logFor(ctx).WithError(err).Error("failed to commit set main transaction")
 		return errors.NewAppError("failed to commit set main transaction", err)
 	}
 
-	pr.log.WithFields(logrus.Fields{
+	pr.logFor(ctx).WithFields(logrus.Fields{
 		"blizzard_id": blizzardID,
 		"character":   charName,
 	}).Infof("Set main for %s succeeded", charName)
@@ -381,53 +530,1369 @@ func (pr *postgresRepository) SetMainCharacter(ctx context.Context, blizzardID,
 	return nil
 }
 
-func (pr *postgresRepository) GetCharacterByName(ctx context.Context, charName string) (*entity.Character, error) {
-	query, args, err := psql.Select(
-		"character_id",
-		"blizzard_id",
-		"battletag",
-		"name",
-		"realm",
-		"race",
-		"faction",
-		"class",
-		"spec",
-		"lvl",
-		"ilvl",
-		"guild",
-		"mythic_score",
-		"is_main",
-	).From("profile").
-		Where(sq.ILike{"name": charName}).
+func (pr *postgresRepository) SavePreference(ctx context.Context, blizzardID, realmSlug, charName string, pref entity.Preference) error {
+	characterID, err := pr.characterIDByName(ctx, blizzardID, realmSlug, charName)
+	if err != nil {
+		return err
+	}
+
+	query, args, err := psql.
+		Insert("profile_preference").
+		Columns("character_id", "blizzard_id", "hidden", "display_order", "note").
+		Values(characterID, blizzardID, pref.Hidden, pref.DisplayOrder, pref.Note).
+		Suffix("ON CONFLICT (character_id) DO UPDATE SET " +
+			"hidden = EXCLUDED.hidden, " +
+			"display_order = EXCLUDED.display_order, " +
+			"note = EXCLUDED.note").
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for save preference")
+		return errors.NewAppError("failed build query for save preference", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, query, args...); err != nil {
+		pr.logFor(ctx).WithError(err).Errorf("failed to save preference for user: %s", blizzardID)
+		return errors.NewAppError("failed to save preference", err)
+	}
+
+	pr.logFor(ctx).WithFields(logrus.Fields{
+		"blizzard_id": blizzardID,
+		"character":   charName,
+	}).Info("Save preference succeeded")
+
+	return nil
+}
+
+// GetCharacterByName finds a character of the account by its exact name,
+// ignoring case, on realmSlug. Without a realm the name must be unique across
+// the account's realms.
+func (pr *postgresRepository) GetCharacterByName(ctx context.Context, blizzardID, realmSlug, charName string) (*entity.Character, error) {
+	query, args, err := selectCharacters().
+		Where(characterNameMatch("p.", blizzardID, realmSlug, charName)).
+		Limit(2).
 		ToSql()
 
 	if err != nil {
-		pr.log.WithError(err).Error("failed build query for get character")
+		pr.logFor(ctx).WithError(err).Error("failed build query for get character")
 		return nil, errors.NewAppError("failed build query for get character", err)
 	}
 
-	var char entity.Character
-	if err := pr.pool.QueryRow(ctx, query, args...).
-		Scan(
-			&char.CharacterID,
-			&char.BlizzardID,
-			&char.Battletag,
-			&char.Name,
-			&char.Realm,
-			&char.Race,
-			&char.Faction,
-			&char.Class,
-			&char.Spec,
-			&char.Lvl,
-			&char.Ilvl,
-			&char.Guild,
-			&char.MythicScore,
-			&char.IsMain,
-		); err != nil {
-		pr.log.WithError(err).Error("failed to scan character row")
-		return nil, errors.NewAppError("failed to scan character row", err)
+	rows, err := pr.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed execute SQL get character")
+		return nil, errors.NewAppError("failed execute SQL get character", err)
 	}
+	defer rows.Close()
 
-	pr.log.Infof("%s get succeeded", charName)
-	return &char, nil
+	matches := make([]entity.Character, 0, 2)
+	for rows.Next() {
+		char, err := scanCharacter(rows)
+		if err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan character row")
+			return nil, errors.NewAppError("failed to scan character row", err)
+		}
+		matches = append(matches, char)
+	}
+
+	if err := rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows error")
+		return nil, errors.NewAppError("rows error", err)
+	}
+
+	switch len(matches) {
+	case 0:
+		pr.logFor(ctx).WithField("character", charName).Info("character not found")
+		return nil, ErrCharacterNotFound
+	case 1:
+		pr.logFor(ctx).Infof("%s get succeeded", charName)
+		return &matches[0], nil
+	default:
+		pr.logFor(ctx).WithField("character", charName).Info("character name is ambiguous")
+		return nil, ErrCharacterAmbiguous
+	}
+}
+
+// characterIDByName resolves a name the way GetCharacterByName does, on the
+// repository's own connection so it sees writes of the current transaction.
+func (pr *postgresRepository) characterIDByName(ctx context.Context, blizzardID, realmSlug, charName string) (int, error) {
+	query, args, err := psql.
+		Select("character_id").
+		From("profile").
+		Where(characterNameMatch("", blizzardID, realmSlug, charName)).
+		Limit(2).
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for resolve character")
+		return 0, errors.NewAppError("failed build query for resolve character", err)
+	}
+
+	rows, err := pr.db.Query(ctx, query, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed execute SQL resolve character")
+		return 0, errors.NewAppError("failed execute SQL resolve character", err)
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0, 2)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan character id")
+			return 0, errors.NewAppError("failed to scan character id", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows error")
+		return 0, errors.NewAppError("rows error", err)
+	}
+
+	switch len(ids) {
+	case 0:
+		pr.logFor(ctx).WithField("character", charName).Info("character not found")
+		return 0, ErrCharacterNotFound
+	case 1:
+		return ids[0], nil
+	default:
+		pr.logFor(ctx).WithField("character", charName).Info("character name is ambiguous")
+		return 0, ErrCharacterAmbiguous
+	}
+}
+
+// characterNameMatch matches a character of blizzardID by exact name, ignoring
+// case, and by realm when realmSlug is set. Unlike ILIKE, "%" and "_" in the
+// name are plain characters.
+func characterNameMatch(prefix, blizzardID, realmSlug, charName string) sq.And {
+	match := sq.And{
+		sq.Eq{prefix + "blizzard_id": blizzardID},
+		sq.Expr("lower("+prefix+"name) = lower(?)", charName),
+	}
+	if realmSlug != "" {
+		match = append(match, sq.Eq{prefix + "realm_slug": realmSlug})
+	}
+	return match
+}
+
+func (pr *postgresRepository) GetAccountSummary(ctx context.Context, blizzardID string, maxLevel int) (*entity.AccountSummary, error) {
+	query, args, err := selectCharacters().
+		Columns(
+			"COALESCE(g.guild_id, 0)",
+			"COALESCE(g.name, '')",
+			"COALESCE(g.name_slug, '')",
+			"COALESCE(g.realm, '')",
+			"COALESCE(g.realm_slug, '')",
+			"COALESCE(g.faction, '')",
+		).
+		Column("count(*) FILTER (WHERE p.lvl >= ? AND NOT COALESCE(pp.is_main, false)) OVER ()", maxLevel).
+		Column("COALESCE(max(p.mythic_score) OVER (), 0)").
+		Column("COALESCE(avg(p.ilvl) FILTER (WHERE p.lvl >= ?) OVER (), 0)", maxLevel).
+		LeftJoin("guild g ON g.character_id = p.character_id").
+		Where(sq.Eq{"p.blizzard_id": blizzardID}).
+		Where("NOT COALESCE(pp.hidden, false)").
+		OrderBy("pp.display_order NULLS LAST", "p.mythic_score DESC").
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for get account summary")
+		return nil, errors.NewAppError("failed build query for get account summary", err)
+	}
+
+	rows, err := pr.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed execute SQL get account summary")
+		return nil, errors.NewAppError("failed execute SQL get account summary", err)
+	}
+	defer rows.Close()
+
+	summary := &entity.AccountSummary{
+		BlizzardID: blizzardID,
+		Alts:       make([]entity.Character, 0),
+		Guilds:     make([]entity.Guild, 0),
+	}
+	seenGuilds := make(map[int]struct{})
+	found := false
+
+	for rows.Next() {
+		var g entity.Guild
+		char, err := scanCharacter(rows,
+			&g.GuildID,
+			&g.Name,
+			&g.NameSlug,
+			&g.Realm,
+			&g.RealmSlug,
+			&g.Faction,
+			&summary.MaxLevelAlts,
+			&summary.HighestMythicScore,
+			&summary.AverageIlvl,
+		)
+		if err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan account summary rows")
+			return nil, errors.NewAppError("failed to scan account summary rows", err)
+		}
+		found = true
+		summary.Battletag = char.Battletag
+
+		if char.IsMain {
+			main := char
+			summary.Main = &main
+		} else {
+			summary.Alts = append(summary.Alts, char)
+		}
+
+		if _, ok := seenGuilds[g.GuildID]; g.GuildID != 0 && !ok {
+			seenGuilds[g.GuildID] = struct{}{}
+			g.CharacterID = char.CharacterID
+			summary.Guilds = append(summary.Guilds, g)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows error")
+		return nil, errors.NewAppError("rows error", err)
+	}
+
+	if !found {
+		pr.logFor(ctx).WithField("blizzard_id", blizzardID).Info("characters not found")
+		return nil, errors.NewAppError("characters not found", nil)
+	}
+
+	pr.logFor(ctx).Infof("Account summary for %s get succeeded", blizzardID)
+	return summary, nil
+}
+
+func (pr *postgresRepository) GetGuildLeaderboard(ctx context.Context, filter entity.LeaderboardFilter) (*entity.LeaderboardPage, error) {
+	sortColumn := "p.mythic_score"
+	if filter.SortBy == entity.LeaderboardByIlvl {
+		sortColumn = "p.ilvl"
+	}
+
+	query := selectCharacters().
+		Join("guild g ON g.character_id = p.character_id").
+		Where(sq.Eq{"g.guild_id": filter.GuildID}).
+		Where("NOT COALESCE(pp.hidden, false)").
+		OrderBy(sortColumn+" DESC", "p.character_id DESC").
+		Limit(uint64(filter.Limit + 1))
+
+	if filter.Class != "" {
+		query = query.Where(ilikeEqual("p.class", filter.Class))
+	}
+	if filter.Spec != "" {
+		query = query.Where(ilikeEqual("p.spec", filter.Spec))
+	}
+	switch filter.Role {
+	case entity.RoleTank, entity.RoleHealer:
+		query = query.Where(sq.Eq{"lower(p.spec)": entity.RoleSpecs(filter.Role)})
+	case entity.RoleDamage:
+		query = query.Where(sq.NotEq{"lower(COALESCE(p.spec, ''))": entity.NonDamageSpecs()})
+	}
+	if filter.MinLevel > 0 {
+		query = query.Where(sq.GtOrEq{"p.lvl": filter.MinLevel})
+	}
+	if filter.Faction != "" {
+		query = query.Where(ilikeEqual("p.faction", filter.Faction))
+	}
+	if filter.After != nil {
+		var after any = filter.After.Value
+		if filter.SortBy == entity.LeaderboardByIlvl {
+			after = int(filter.After.Value)
+		}
+		query = query.Where("("+sortColumn+", p.character_id) < (?, ?)", after, filter.After.CharacterID)
+	}
+
+	sql, args, err := query.ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for get guild leaderboard")
+		return nil, errors.NewAppError("failed build query for get guild leaderboard", err)
+	}
+
+	rows, err := pr.reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed execute SQL get guild leaderboard")
+		return nil, errors.NewAppError("failed execute SQL get guild leaderboard", err)
+	}
+	defer rows.Close()
+
+	page := &entity.LeaderboardPage{
+		GuildID: filter.GuildID,
+		SortBy:  filter.SortBy,
+		Entries: make([]entity.Character, 0, filter.Limit),
+	}
+
+	for rows.Next() {
+		char, err := scanCharacter(rows)
+		if err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan leaderboard rows")
+			return nil, errors.NewAppError("failed to scan leaderboard rows", err)
+		}
+		page.Entries = append(page.Entries, char)
+	}
+
+	if err = rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows error")
+		return nil, errors.NewAppError("rows error", err)
+	}
+
+	if len(page.Entries) > filter.Limit {
+		page.Entries = page.Entries[:filter.Limit]
+		last := page.Entries[len(page.Entries)-1]
+		cursor := entity.LeaderboardCursor{Value: last.MythicScore, CharacterID: last.CharacterID}
+		if filter.SortBy == entity.LeaderboardByIlvl {
+			cursor.Value = float64(last.Ilvl)
+		}
+		page.NextCursor = cursor.Encode()
+	}
+
+	pr.logFor(ctx).Infof("Got %d leaderboard entries for guild %d", len(page.Entries), filter.GuildID)
+	return page, nil
+}
+
+func (pr *postgresRepository) Search(ctx context.Context, filter entity.SearchFilter) (*entity.SearchResult, error) {
+	q := strings.ToLower(filter.Query)
+	contains := "%" + likeEscaper.Replace(q) + "%"
+
+	result := &entity.SearchResult{
+		Query:      filter.Query,
+		Characters: make([]entity.CharacterMatch, 0),
+		Guilds:     make([]entity.GuildMatch, 0),
+	}
+
+	charQuery := selectCharacters().
+		Column("similarity(lower(p.name), ?) AS score", q).
+		LeftJoin("guild g ON g.character_id = p.character_id").
+		Where("NOT COALESCE(pp.hidden, false)").
+		Where("(lower(p.name) % ? OR lower(p.name) LIKE ?)", q, contains).
+		OrderBy("score DESC", "p.name").
+		Limit(uint64(filter.Limit))
+	if filter.Realm != "" {
+		charQuery = charQuery.Where(sq.Eq{"p.realm_slug": filter.Realm})
+	}
+	if filter.Class != "" {
+		charQuery = charQuery.Where(ilikeEqual("p.class", filter.Class))
+	}
+	if filter.Guild != "" {
+		charQuery = charQuery.Where(sq.Or{ilikeEqual("p.guild", filter.Guild), sq.Eq{"g.name_slug": filter.Guild}})
+	}
+
+	sql, args, err := charQuery.ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for search characters")
+		return nil, errors.NewAppError("failed build query for search characters", err)
+	}
+
+	rows, err := pr.reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed execute SQL search characters")
+		return nil, errors.NewAppError("failed execute SQL search characters", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var match entity.CharacterMatch
+		match.Character, err = scanCharacter(rows, &match.Similarity)
+		if err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan search character rows")
+			return nil, errors.NewAppError("failed to scan search character rows", err)
+		}
+		result.Characters = append(result.Characters, match)
+	}
+
+	if err = rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows error")
+		return nil, errors.NewAppError("rows error", err)
+	}
+
+	guildMatches := psql.
+		Select(
+			"DISTINCT ON (g.guild_id) g.character_id",
+			"g.guild_id",
+			"g.name",
+			"g.name_slug",
+			"g.realm",
+			"g.realm_slug",
+			"g.faction",
+		).
+		Column("similarity(lower(g.name), ?) AS score", q).
+		From("guild g").
+		Where("(lower(g.name) % ? OR lower(g.name) LIKE ?)", q, contains).
+		OrderBy("g.guild_id", "score DESC")
+	if filter.Realm != "" {
+		guildMatches = guildMatches.Where(sq.Eq{"g.realm_slug": filter.Realm})
+	}
+
+	sql, args, err = psql.
+		Select("character_id", "guild_id", "name", "name_slug", "realm", "realm_slug", "faction", "score").
+		FromSelect(guildMatches, "m").
+		OrderBy("score DESC", "name").
+		Limit(uint64(filter.Limit)).
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for search guilds")
+		return nil, errors.NewAppError("failed build query for search guilds", err)
+	}
+
+	guildRows, err := pr.reader(ctx).Query(ctx, sql, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed execute SQL search guilds")
+		return nil, errors.NewAppError("failed execute SQL search guilds", err)
+	}
+	defer guildRows.Close()
+
+	for guildRows.Next() {
+		var match entity.GuildMatch
+		if err := guildRows.Scan(
+			&match.CharacterID,
+			&match.GuildID,
+			&match.Name,
+			&match.NameSlug,
+			&match.Realm,
+			&match.RealmSlug,
+			&match.Faction,
+			&match.Similarity,
+		); err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan search guild rows")
+			return nil, errors.NewAppError("failed to scan search guild rows", err)
+		}
+		result.Guilds = append(result.Guilds, match)
+	}
+
+	if err = guildRows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows error")
+		return nil, errors.NewAppError("rows error", err)
+	}
+
+	pr.logFor(ctx).Infof("Search %q found %d characters and %d guilds", filter.Query, len(result.Characters), len(result.Guilds))
+	return result, nil
+}
+
+func (pr *postgresRepository) SaveRealms(ctx context.Context, realms []slug.Realm) error {
+	if len(realms) == 0 {
+		return nil
+	}
+
+	query := psql.
+		Insert("realm").
+		Columns("id", "slug", "names", "updated_at")
+
+	for _, r := range realms {
+		names, err := json.Marshal(r.Names)
+		if err != nil {
+			pr.logFor(ctx).WithError(err).WithField("realm", r.Slug).Error("failed marshal realm names")
+			return errors.NewAppError("failed marshal realm names", err)
+		}
+		query = query.Values(r.ID, r.Slug, names, sq.Expr("now()"))
+	}
+
+	sql, args, err := query.Suffix("ON CONFLICT (id) DO UPDATE SET " +
+		"slug = EXCLUDED.slug, " +
+		"names = EXCLUDED.names, " +
+		"updated_at = EXCLUDED.updated_at").
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for save realms")
+		return errors.NewAppError("failed build query for save realms", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, sql, args...); err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed execute SQL save realms")
+		return errors.NewAppError("failed execute SQL save realms", err)
+	}
+
+	pr.logFor(ctx).Infof("Saved/updated %d realms", len(realms))
+	return nil
+}
+
+func (pr *postgresRepository) GetRealms(ctx context.Context) ([]slug.Realm, error) {
+	query, args, err := psql.
+		Select("id", "slug", "names").
+		From("realm").
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for get realms")
+		return nil, errors.NewAppError("failed build query for get realms", err)
+	}
+
+	rows, err := pr.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed execute SQL get realms")
+		return nil, errors.NewAppError("failed execute SQL get realms", err)
+	}
+	defer rows.Close()
+
+	realms := make([]slug.Realm, 0)
+	for rows.Next() {
+		var r slug.Realm
+		if err := rows.Scan(&r.ID, &r.Slug, &r.Names); err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan realm rows")
+			return nil, errors.NewAppError("failed to scan realm rows", err)
+		}
+		realms = append(realms, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows error")
+		return nil, errors.NewAppError("rows error", err)
+	}
+
+	return realms, nil
+}
+
+func (pr *postgresRepository) SaveSyncState(ctx context.Context, blizzardID string, characters int) error {
+	query, args, err := psql.
+		Insert("profile_sync").
+		Columns("blizzard_id", "characters", "synced_at").
+		Values(blizzardID, characters, sq.Expr("now()")).
+		Suffix("ON CONFLICT (blizzard_id) DO UPDATE SET " +
+			"characters = EXCLUDED.characters, " +
+			"synced_at = EXCLUDED.synced_at").
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for save sync state")
+		return errors.NewAppError("failed build query for save sync state", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, query, args...); err != nil {
+		pr.logFor(ctx).WithError(err).Errorf("failed to save sync state for user: %s", blizzardID)
+		return errors.NewAppError("failed to save sync state", err)
+	}
+
+	return nil
+}
+
+func (pr *postgresRepository) SaveHTTPValidators(ctx context.Context, validators []entity.HTTPValidator) error {
+	if len(validators) == 0 {
+		return nil
+	}
+
+	builder := psql.
+		Insert("http_cache").
+		Columns("url", "etag", "last_modified", "updated_at")
+	for _, v := range validators {
+		builder = builder.Values(v.URL, v.ETag, v.LastModified, sq.Expr("now()"))
+	}
+
+	query, args, err := builder.
+		Suffix("ON CONFLICT (url) DO UPDATE SET " +
+			"etag = EXCLUDED.etag, " +
+			"last_modified = EXCLUDED.last_modified, " +
+			"updated_at = EXCLUDED.updated_at").
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for save http validators")
+		return errors.NewAppError("failed build query for save http validators", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, query, args...); err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to save http validators")
+		return errors.NewAppError("failed to save http validators", err)
+	}
+
+	return nil
+}
+
+func (pr *postgresRepository) GetHTTPValidators(ctx context.Context, urls []string) (map[string]entity.HTTPValidator, error) {
+	validators := make(map[string]entity.HTTPValidator, len(urls))
+	if len(urls) == 0 {
+		return validators, nil
+	}
+
+	query, args, err := psql.
+		Select("url", "etag", "last_modified").
+		From("http_cache").
+		Where(sq.Eq{"url": urls}).
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for get http validators")
+		return nil, errors.NewAppError("failed build query for get http validators", err)
+	}
+
+	rows, err := pr.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to get http validators")
+		return nil, errors.NewAppError("failed to get http validators", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v entity.HTTPValidator
+		if err := rows.Scan(&v.URL, &v.ETag, &v.LastModified); err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan http validator row")
+			return nil, errors.NewAppError("failed to scan http validator row", err)
+		}
+		validators[v.URL] = v
+	}
+
+	if err := rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows iteration error")
+		return nil, errors.NewAppError("rows iteration error", err)
+	}
+
+	return validators, nil
+}
+
+// ReplaceGuildMembers makes the stored roster of guildID equal to members:
+// current members are upserted, everyone else is removed.
+func (pr *postgresRepository) ReplaceGuildMembers(ctx context.Context, guildID int, members []entity.GuildMember) error {
+	ids := make([]int, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.CharacterID)
+	}
+
+	query, args, err := psql.
+		Delete("guild_member").
+		Where(sq.Eq{"guild_id": guildID}).
+		Where(sq.NotEq{"character_id": ids}).
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for delete guild members")
+		return errors.NewAppError("failed build query for delete guild members", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, query, args...); err != nil {
+		pr.logFor(ctx).WithError(err).Errorf("failed to delete guild members for guild: %d", guildID)
+		return errors.NewAppError("failed to delete guild members", err)
+	}
+
+	if len(members) == 0 {
+		return nil
+	}
+
+	builder := psql.
+		Insert("guild_member").
+		Columns("guild_id", "character_id", "name", "realm_slug", "level", "class_id", "rank", "updated_at")
+	for _, m := range members {
+		builder = builder.Values(guildID, m.CharacterID, m.Name, m.RealmSlug, m.Level, m.ClassID, m.Rank, sq.Expr("now()"))
+	}
+
+	query, args, err = builder.
+		Suffix("ON CONFLICT (guild_id, character_id) DO UPDATE SET " +
+			"name = EXCLUDED.name, " +
+			"realm_slug = EXCLUDED.realm_slug, " +
+			"level = EXCLUDED.level, " +
+			"class_id = EXCLUDED.class_id, " +
+			"rank = EXCLUDED.rank, " +
+			"updated_at = EXCLUDED.updated_at").
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for save guild members")
+		return errors.NewAppError("failed build query for save guild members", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, query, args...); err != nil {
+		pr.logFor(ctx).WithError(err).Errorf("failed to save guild members for guild: %d", guildID)
+		return errors.NewAppError("failed to save guild members", err)
+	}
+
+	return nil
+}
+
+// GetGuildMembers returns the stored roster of guildID. Inside a transaction
+// the rows are locked so concurrent syncs of one guild diff in turn.
+func (pr *postgresRepository) GetGuildMembers(ctx context.Context, guildID int) ([]entity.GuildMember, error) {
+	builder := psql.
+		Select("guild_id", "character_id", "name", "realm_slug", "level", "class_id", "rank").
+		From("guild_member").
+		Where(sq.Eq{"guild_id": guildID}).
+		OrderBy("character_id")
+	if pr.inTx {
+		builder = builder.Suffix("FOR UPDATE")
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for get guild members")
+		return nil, errors.NewAppError("failed build query for get guild members", err)
+	}
+
+	rows, err := pr.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Errorf("failed to get guild members for guild: %d", guildID)
+		return nil, errors.NewAppError("failed to get guild members", err)
+	}
+	defer rows.Close()
+
+	members := make([]entity.GuildMember, 0)
+	for rows.Next() {
+		var m entity.GuildMember
+		if err := rows.Scan(&m.GuildID, &m.CharacterID, &m.Name, &m.RealmSlug, &m.Level, &m.ClassID, &m.Rank); err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan guild member row")
+			return nil, errors.NewAppError("failed to scan guild member row", err)
+		}
+		members = append(members, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows iteration error")
+		return nil, errors.NewAppError("rows iteration error", err)
+	}
+
+	return members, nil
+}
+
+func (pr *postgresRepository) SaveGuildEvents(ctx context.Context, events []entity.GuildEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	builder := psql.
+		Insert("guild_event").
+		Columns("guild_id", "character_id", "name", "realm_slug", "type", "old_rank", "new_rank", "occurred_at")
+	for _, e := range events {
+		builder = builder.Values(e.GuildID, e.CharacterID, e.Name, e.RealmSlug, e.Type, e.OldRank, e.NewRank, e.OccurredAt)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for save guild events")
+		return errors.NewAppError("failed build query for save guild events", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, query, args...); err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to save guild events")
+		return errors.NewAppError("failed to save guild events", err)
+	}
+
+	return nil
+}
+
+func (pr *postgresRepository) GetGuildEvents(ctx context.Context, filter entity.GuildEventFilter) (*entity.GuildEventPage, error) {
+	builder := psql.
+		Select("id", "guild_id", "character_id", "name", "realm_slug", "type", "old_rank", "new_rank", "occurred_at").
+		From("guild_event").
+		Where(sq.Eq{"guild_id": filter.GuildID}).
+		OrderBy("occurred_at DESC", "id DESC").
+		Limit(uint64(filter.Limit + 1))
+
+	if filter.Type != "" {
+		builder = builder.Where(sq.Eq{"type": filter.Type})
+	}
+	if !filter.Since.IsZero() {
+		builder = builder.Where(sq.GtOrEq{"occurred_at": filter.Since})
+	}
+	if !filter.Until.IsZero() {
+		builder = builder.Where(sq.Lt{"occurred_at": filter.Until})
+	}
+	if filter.After != nil {
+		builder = builder.Where("(occurred_at, id) < (?, ?)", filter.After.OccurredAt, filter.After.ID)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for get guild events")
+		return nil, errors.NewAppError("failed build query for get guild events", err)
+	}
+
+	rows, err := pr.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed execute SQL get guild events")
+		return nil, errors.NewAppError("failed execute SQL get guild events", err)
+	}
+	defer rows.Close()
+
+	page := &entity.GuildEventPage{
+		GuildID: filter.GuildID,
+		Events:  make([]entity.GuildEvent, 0, filter.Limit),
+	}
+
+	for rows.Next() {
+		var e entity.GuildEvent
+		if err := rows.Scan(&e.ID, &e.GuildID, &e.CharacterID, &e.Name, &e.RealmSlug, &e.Type, &e.OldRank, &e.NewRank, &e.OccurredAt); err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan guild event row")
+			return nil, errors.NewAppError("failed to scan guild event row", err)
+		}
+		page.Events = append(page.Events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows error")
+		return nil, errors.NewAppError("rows error", err)
+	}
+
+	if len(page.Events) > filter.Limit {
+		page.Events = page.Events[:filter.Limit]
+		last := page.Events[len(page.Events)-1]
+		page.NextCursor = entity.GuildEventCursor{OccurredAt: last.OccurredAt, ID: last.ID}.Encode()
+	}
+
+	pr.logFor(ctx).Infof("Got %d events for guild %d", len(page.Events), filter.GuildID)
+	return page, nil
+}
+
+// ExportCharacters streams stored characters to fn, all of them or only one
+// account's when blizzardID is set.
+func (pr *postgresRepository) ExportCharacters(ctx context.Context, blizzardID string, fn func(entity.Character) error) error {
+	builder := selectCharacters().OrderBy("p.blizzard_id", "p.character_id")
+	if blizzardID != "" {
+		builder = builder.Where(sq.Eq{"p.blizzard_id": blizzardID})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for export characters")
+		return errors.NewAppError("failed build query for export characters", err)
+	}
+
+	rows, err := pr.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to export characters")
+		return errors.NewAppError("failed to export characters", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		char, err := scanCharacter(rows)
+		if err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan character row")
+			return errors.NewAppError("failed to scan character row", err)
+		}
+		if err := fn(char); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows iteration error")
+		return errors.NewAppError("rows iteration error", err)
+	}
+
+	return nil
+}
+
+func (pr *postgresRepository) Ping(ctx context.Context) error {
+	return pr.pool.Ping(ctx)
+}
+
+func (pr *postgresRepository) MigrationVersion(ctx context.Context) (int64, bool, error) {
+	query, args, err := psql.
+		Select("version", "dirty").
+		From("schema_migrations").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for migration version")
+		return 0, false, errors.NewAppError("failed build query for migration version", err)
+	}
+
+	var (
+		version int64
+		dirty   bool
+	)
+	if err := pr.db.QueryRow(ctx, query, args...).Scan(&version, &dirty); err != nil {
+		if err == pgx.ErrNoRows {
+			return 0, false, errors.NewAppError("no migrations applied", err)
+		}
+		return 0, false, errors.NewAppError("failed to read migration version", err)
+	}
+
+	return version, dirty, nil
+}
+
+// IsGuildMember reports whether any stored character of blizzardID is in guildID.
+func (pr *postgresRepository) IsGuildMember(ctx context.Context, blizzardID string, guildID int) (bool, error) {
+	query, args, err := psql.
+		Select("1").
+		From("guild g").
+		Join("profile p ON p.character_id = g.character_id").
+		Where(sq.Eq{"g.guild_id": guildID, "p.blizzard_id": blizzardID}).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for guild membership")
+		return false, errors.NewAppError("failed build query for guild membership", err)
+	}
+
+	var member bool
+	if err := pr.reader(ctx).QueryRow(ctx, query, args...).Scan(&member); err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to check guild membership")
+		return false, errors.NewAppError("failed to check guild membership", err)
+	}
+
+	return member, nil
+}
+
+func (pr *postgresRepository) CreateWebhookSubscription(ctx context.Context, sub *entity.WebhookSubscription) error {
+	query, args, err := psql.
+		Insert("webhook_subscription").
+		Columns("blizzard_id", "url", "secret", "event_types", "guild_id", "active").
+		Values(sub.BlizzardID, sub.URL, sub.Secret, sub.EventTypes, sub.GuildID, sub.Active).
+		Suffix("RETURNING id, created_at").
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for create webhook subscription")
+		return errors.NewAppError("failed build query for create webhook subscription", err)
+	}
+
+	if err := pr.db.QueryRow(ctx, query, args...).Scan(&sub.ID, &sub.CreatedAt); err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to create webhook subscription")
+		return errors.NewAppError("failed to create webhook subscription", err)
+	}
+
+	return nil
+}
+
+func (pr *postgresRepository) GetWebhookSubscriptions(ctx context.Context, blizzardID string) ([]entity.WebhookSubscription, error) {
+	query, args, err := psql.
+		Select("id", "blizzard_id", "url", "event_types", "guild_id", "active", "created_at").
+		From("webhook_subscription").
+		Where(sq.Eq{"blizzard_id": blizzardID}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for get webhook subscriptions")
+		return nil, errors.NewAppError("failed build query for get webhook subscriptions", err)
+	}
+
+	rows, err := pr.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to get webhook subscriptions")
+		return nil, errors.NewAppError("failed to get webhook subscriptions", err)
+	}
+	defer rows.Close()
+
+	subs := make([]entity.WebhookSubscription, 0)
+	for rows.Next() {
+		var sub entity.WebhookSubscription
+		if err := rows.Scan(&sub.ID, &sub.BlizzardID, &sub.URL, &sub.EventTypes, &sub.GuildID, &sub.Active, &sub.CreatedAt); err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan webhook subscription row")
+			return nil, errors.NewAppError("failed to scan webhook subscription row", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows iteration error")
+		return nil, errors.NewAppError("rows iteration error", err)
+	}
+
+	return subs, nil
+}
+
+func (pr *postgresRepository) DeleteWebhookSubscription(ctx context.Context, blizzardID string, id int64) error {
+	query, args, err := psql.
+		Delete("webhook_subscription").
+		Where(sq.Eq{"id": id, "blizzard_id": blizzardID}).
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for delete webhook subscription")
+		return errors.NewAppError("failed build query for delete webhook subscription", err)
+	}
+
+	tag, err := pr.db.Exec(ctx, query, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to delete webhook subscription")
+		return errors.NewAppError("failed to delete webhook subscription", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.NewAppError("webhook subscription not found", nil)
+	}
+
+	return nil
+}
+
+// SaveOutboxEvents stores webhook events; call it inside the transaction that
+// makes the change so an event exists exactly when the change does.
+func (pr *postgresRepository) SaveOutboxEvents(ctx context.Context, events []entity.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	builder := psql.
+		Insert("webhook_outbox").
+		Columns("event_type", "blizzard_id", "guild_id", "payload")
+	for _, e := range events {
+		var blizzardID, guildID any
+		if e.BlizzardID != "" {
+			blizzardID = e.BlizzardID
+		}
+		if e.GuildID != 0 {
+			guildID = e.GuildID
+		}
+		builder = builder.Values(e.Type, blizzardID, guildID, e.Payload)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for save outbox events")
+		return errors.NewAppError("failed build query for save outbox events", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, query, args...); err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to save outbox events")
+		return errors.NewAppError("failed to save outbox events", err)
+	}
+
+	return nil
+}
+
+// fanOutSQL marks a batch of outbox events processed and queues one delivery
+// per matching active subscription. SKIP LOCKED lets several instances run
+// the dispatcher side by side.
+const fanOutSQL = `
+WITH claimed AS (
+	UPDATE webhook_outbox SET processed_at = now()
+	WHERE id IN (
+		SELECT id FROM webhook_outbox
+		WHERE processed_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING id, event_type, blizzard_id, guild_id, payload
+)
+INSERT INTO webhook_delivery (subscription_id, outbox_id, event_type, payload)
+SELECT s.id, c.id, c.event_type, c.payload
+FROM claimed c
+JOIN webhook_subscription s
+	ON s.active
+	AND c.event_type = ANY (s.event_types)
+	AND (s.blizzard_id = c.blizzard_id OR s.guild_id = c.guild_id)
+ON CONFLICT (subscription_id, outbox_id) DO NOTHING`
+
+func (pr *postgresRepository) FanOutOutbox(ctx context.Context, limit int) (int64, error) {
+	tag, err := pr.db.Exec(ctx, fanOutSQL, limit)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to fan out webhook outbox")
+		return 0, errors.NewAppError("failed to fan out webhook outbox", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// claimDeliveriesSQL leases due deliveries by pushing next_attempt_at past the
+// delivery timeout, so a crashed dispatcher's work is picked up again later.
+const claimDeliveriesSQL = `
+UPDATE webhook_delivery d
+SET next_attempt_at = now() + make_interval(secs => $2)
+FROM webhook_subscription s
+WHERE s.id = d.subscription_id
+	AND d.id IN (
+		SELECT id FROM webhook_delivery
+		WHERE status = 'pending' AND next_attempt_at <= now()
+		ORDER BY next_attempt_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	)
+RETURNING d.id, d.subscription_id, d.outbox_id, d.event_type, d.payload, d.status, d.attempts, d.created_at, s.url, s.secret`
+
+func (pr *postgresRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	rows, err := pr.db.Query(ctx, claimDeliveriesSQL, limit, lease.Seconds())
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to claim webhook deliveries")
+		return nil, errors.NewAppError("failed to claim webhook deliveries", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]entity.WebhookDelivery, 0)
+	for rows.Next() {
+		var d entity.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.OutboxID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.CreatedAt, &d.URL, &d.Secret); err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan webhook delivery row")
+			return nil, errors.NewAppError("failed to scan webhook delivery row", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows iteration error")
+		return nil, errors.NewAppError("rows iteration error", err)
+	}
+
+	return deliveries, nil
+}
+
+func (pr *postgresRepository) FinishWebhookDelivery(ctx context.Context, d entity.WebhookDelivery) error {
+	query, args, err := psql.
+		Update("webhook_delivery").
+		Set("status", d.Status).
+		Set("attempts", d.Attempts).
+		Set("response_code", d.ResponseCode).
+		Set("last_error", d.LastError).
+		Set("next_attempt_at", d.NextAttemptAt).
+		Set("delivered_at", d.DeliveredAt).
+		Where(sq.Eq{"id": d.ID}).
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for finish webhook delivery")
+		return errors.NewAppError("failed build query for finish webhook delivery", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, query, args...); err != nil {
+		pr.logFor(ctx).WithError(err).Errorf("failed to update webhook delivery: %d", d.ID)
+		return errors.NewAppError("failed to update webhook delivery", err)
+	}
+
+	return nil
+}
+
+func (pr *postgresRepository) GetWebhookDeliveries(ctx context.Context, blizzardID string, subscriptionID int64, limit int) ([]entity.WebhookDelivery, error) {
+	query, args, err := psql.
+		Select("d.id", "d.subscription_id", "d.outbox_id", "d.event_type", "d.payload", "d.status", "d.attempts",
+			"d.response_code", "d.last_error", "d.next_attempt_at", "d.created_at", "d.delivered_at").
+		From("webhook_delivery d").
+		Join("webhook_subscription s ON s.id = d.subscription_id").
+		Where(sq.Eq{"s.id": subscriptionID, "s.blizzard_id": blizzardID}).
+		OrderBy("d.id DESC").
+		Limit(uint64(limit)).
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for get webhook deliveries")
+		return nil, errors.NewAppError("failed build query for get webhook deliveries", err)
+	}
+
+	rows, err := pr.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to get webhook deliveries")
+		return nil, errors.NewAppError("failed to get webhook deliveries", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]entity.WebhookDelivery, 0)
+	for rows.Next() {
+		var d entity.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.OutboxID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.ResponseCode, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &d.DeliveredAt); err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan webhook delivery row")
+			return nil, errors.NewAppError("failed to scan webhook delivery row", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows iteration error")
+		return nil, errors.NewAppError("rows iteration error", err)
+	}
+
+	return deliveries, nil
+}
+
+// PruneWebhooks drops processed outbox events and finished deliveries older
+// than before.
+func (pr *postgresRepository) PruneWebhooks(ctx context.Context, before time.Time) error {
+	outboxQuery, outboxArgs, err := psql.
+		Delete("webhook_outbox").
+		Where(sq.Lt{"processed_at": before}).
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for prune webhook outbox")
+		return errors.NewAppError("failed build query for prune webhook outbox", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, outboxQuery, outboxArgs...); err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to prune webhook outbox")
+		return errors.NewAppError("failed to prune webhook outbox", err)
+	}
+
+	deliveryQuery, deliveryArgs, err := psql.
+		Delete("webhook_delivery").
+		Where(sq.NotEq{"status": entity.WebhookDeliveryPending}).
+		Where(sq.Lt{"created_at": before}).
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for prune webhook deliveries")
+		return errors.NewAppError("failed build query for prune webhook deliveries", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, deliveryQuery, deliveryArgs...); err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to prune webhook deliveries")
+		return errors.NewAppError("failed to prune webhook deliveries", err)
+	}
+
+	return nil
+}
+
+// SaveDomainEvents stores domain events for the relay; call it inside the
+// transaction that makes the change so an event exists exactly when the change
+// does.
+func (pr *postgresRepository) SaveDomainEvents(ctx context.Context, events []entity.DomainEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	builder := psql.
+		Insert("domain_event").
+		Columns("id", "type", "version", "source", "occurred_at", "data")
+	for _, e := range events {
+		builder = builder.Values(e.ID, e.Type, e.Version, e.Source, e.OccurredAt, e.Data)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for save domain events")
+		return errors.NewAppError("failed build query for save domain events", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, query, args...); err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to save domain events")
+		return errors.NewAppError("failed to save domain events", err)
+	}
+
+	return nil
+}
+
+// GetUnpublishedDomainEvents returns the oldest events the relay has not
+// published yet. Inside a transaction the rows are locked and rows locked by
+// another relay are skipped, so several instances can relay side by side.
+func (pr *postgresRepository) GetUnpublishedDomainEvents(ctx context.Context, limit int) ([]entity.DomainEvent, error) {
+	builder := psql.
+		Select("id", "type", "version", "source", "occurred_at", "data").
+		From("domain_event").
+		Where(sq.Eq{"published_at": nil}).
+		OrderBy("occurred_at", "id").
+		Limit(uint64(limit))
+	if pr.inTx {
+		builder = builder.Suffix("FOR UPDATE SKIP LOCKED")
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for get unpublished domain events")
+		return nil, errors.NewAppError("failed build query for get unpublished domain events", err)
+	}
+
+	rows, err := pr.db.Query(ctx, query, args...)
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to get unpublished domain events")
+		return nil, errors.NewAppError("failed to get unpublished domain events", err)
+	}
+	defer rows.Close()
+
+	events := make([]entity.DomainEvent, 0, limit)
+	for rows.Next() {
+		var e entity.DomainEvent
+		if err := rows.Scan(&e.ID, &e.Type, &e.Version, &e.Source, &e.OccurredAt, &e.Data); err != nil {
+			pr.logFor(ctx).WithError(err).Error("failed to scan domain event row")
+			return nil, errors.NewAppError("failed to scan domain event row", err)
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		pr.logFor(ctx).WithError(err).Error("rows iteration error")
+		return nil, errors.NewAppError("rows iteration error", err)
+	}
+
+	return events, nil
+}
+
+func (pr *postgresRepository) MarkDomainEventsPublished(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query, args, err := psql.
+		Update("domain_event").
+		Set("published_at", sq.Expr("now()")).
+		Where(sq.Eq{"id": ids}).
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for mark domain events published")
+		return errors.NewAppError("failed build query for mark domain events published", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, query, args...); err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to mark domain events published")
+		return errors.NewAppError("failed to mark domain events published", err)
+	}
+
+	return nil
+}
+
+// PruneDomainEvents deletes published events older than before. Unpublished
+// events are kept however old they are.
+func (pr *postgresRepository) PruneDomainEvents(ctx context.Context, before time.Time) error {
+	query, args, err := psql.
+		Delete("domain_event").
+		Where(sq.NotEq{"published_at": nil}).
+		Where(sq.Lt{"occurred_at": before}).
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for prune domain events")
+		return errors.NewAppError("failed build query for prune domain events", err)
+	}
+
+	if _, err := pr.db.Exec(ctx, query, args...); err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed to prune domain events")
+		return errors.NewAppError("failed to prune domain events", err)
+	}
+
+	return nil
+}
+
+func selectCharacters() sq.SelectBuilder {
+	return psql.Select(
+		"p.character_id",
+		"p.blizzard_id",
+		"p.battletag",
+		"p.name",
+		"p.realm",
+		"COALESCE(p.realm_slug, '')",
+		"p.race",
+		"p.faction",
+		"p.class",
+		"p.spec",
+		"p.lvl",
+		"p.ilvl",
+		"p.guild",
+		"p.mythic_score",
+		"COALESCE(pp.is_main, false)",
+		"COALESCE(pp.hidden, false)",
+		"pp.display_order",
+		"COALESCE(pp.note, '')",
+	).
+		From("profile p").
+		LeftJoin("profile_preference pp ON pp.character_id = p.character_id")
+}
+
+func scanCharacter(row pgx.Row, extra ...any) (entity.Character, error) {
+	var char entity.Character
+	dest := []any{
+		&char.CharacterID,
+		&char.BlizzardID,
+		&char.Battletag,
+		&char.Name,
+		&char.Realm,
+		&char.RealmSlug,
+		&char.Race,
+		&char.Faction,
+		&char.Class,
+		&char.Spec,
+		&char.Lvl,
+		&char.Ilvl,
+		&char.Guild,
+		&char.MythicScore,
+		&char.IsMain,
+		&char.Hidden,
+		&char.DisplayOrder,
+		&char.Note,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	return char, err
+}
+
+func (pr *postgresRepository) GetSyncState(ctx context.Context, blizzardID string) (*entity.SyncState, error) {
+	query, args, err := psql.
+		Select("blizzard_id", "characters", "synced_at").
+		From("profile_sync").
+		Where(sq.Eq{"blizzard_id": blizzardID}).
+		ToSql()
+	if err != nil {
+		pr.logFor(ctx).WithError(err).Error("failed build query for get sync state")
+		return nil, errors.NewAppError("failed build query for get sync state", err)
+	}
+
+	state := entity.SyncState{BlizzardID: blizzardID}
+	err = pr.reader(ctx).QueryRow(ctx, query, args...).Scan(&state.BlizzardID, &state.Characters, &state.SyncedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return &state, nil
+		}
+		pr.logFor(ctx).WithError(err).Error("failed to scan sync state row")
+		return nil, errors.NewAppError("failed to scan sync state row", err)
+	}
+
+	return &state, nil
+}
+
+func (pr *postgresRepository) logFor(ctx context.Context) *logrus.Entry {
+	return logger.FromContext(ctx, pr.log)
 }
@@ -0,0 +1,634 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"profile-service/internal/dbtest"
+	"profile-service/internal/entity"
+	"profile-service/pkg/config"
+	dbpool "profile-service/pkg/db"
+	"profile-service/pkg/slug"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const testBlizzardID = "100"
+
+func newTestRepo(t *testing.T) *postgresRepository {
+	t.Helper()
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return NewPostgresRepository(dbtest.Pool(t), nil, log)
+}
+
+func testCharacter(id int, name string) entity.Character {
+	return entity.Character{
+		CharacterID: id,
+		BlizzardID:  testBlizzardID,
+		Battletag:   "Tester#1234",
+		Name:        name,
+		Realm:       "Silvermoon",
+		RealmSlug:   "silvermoon",
+		Race:        "Human",
+		Faction:     "Alliance",
+		Class:       "Mage",
+		Spec:        "Frost",
+		Lvl:         80,
+		Ilvl:        620,
+		Guild:       "Test Guild",
+		MythicScore: 2450.5,
+	}
+}
+
+func countRows(t *testing.T, repo *postgresRepository, table string) int {
+	t.Helper()
+
+	var n int
+	if err := repo.pool.QueryRow(context.Background(), "SELECT count(*) FROM "+table).Scan(&n); err != nil {
+		t.Fatalf("count %s: %v", table, err)
+	}
+	return n
+}
+
+func TestWithTxRollsBackCharactersWhenALaterSaveFails(t *testing.T) {
+	tests := []struct {
+		name string
+		fail func(ctx context.Context, repo PostgresRepository) error
+	}{
+		{
+			name: "SaveGuilds",
+			fail: func(ctx context.Context, repo PostgresRepository) error {
+				// No stored character 999, so the guild row violates its foreign key.
+				return repo.SaveGuilds(ctx, []entity.Guild{{
+					CharacterID: 999,
+					GuildID:     1,
+					Name:        "Test Guild",
+					NameSlug:    "test-guild",
+					Realm:       "Silvermoon",
+					RealmSlug:   "silvermoon",
+					Faction:     "Alliance",
+				}})
+			},
+		},
+		{
+			name: "SaveSyncState",
+			fail: func(ctx context.Context, repo PostgresRepository) error {
+				return repo.SaveSyncState(ctx, testBlizzardID, math.MaxInt32+1)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepo(t)
+			ctx := context.Background()
+			char := testCharacter(1, "Jaina")
+
+			err := repo.WithTx(ctx, func(tx PostgresRepository) error {
+				if err := tx.SaveCharacters(ctx, []entity.Character{char}); err != nil {
+					t.Fatalf("SaveCharacters: %v", err)
+				}
+				if err := tx.SaveCharacterSnapshots(ctx, entity.ChangedSnapshots(nil, []entity.Character{char})); err != nil {
+					t.Fatalf("SaveCharacterSnapshots: %v", err)
+				}
+				return tt.fail(ctx, tx)
+			})
+			if err == nil {
+				t.Fatalf("WithTx succeeded, want the %s error", tt.name)
+			}
+
+			chars, err := repo.GetCharacters(ctx, testBlizzardID)
+			if err != nil {
+				t.Fatalf("GetCharacters: %v", err)
+			}
+			if len(chars) != 0 {
+				t.Errorf("got %d characters after rollback, want 0", len(chars))
+			}
+			if n := countRows(t, repo, "character_snapshot"); n != 0 {
+				t.Errorf("got %d snapshots after rollback, want 0", n)
+			}
+		})
+	}
+}
+
+func TestWithTxNestedReusesOuterTransaction(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	err := repo.WithTx(ctx, func(outer PostgresRepository) error {
+		if err := outer.SaveCharacters(ctx, []entity.Character{testCharacter(1, "Jaina")}); err != nil {
+			t.Fatalf("SaveCharacters: %v", err)
+		}
+
+		if err := outer.WithTx(ctx, func(inner PostgresRepository) error {
+			if inner != outer {
+				t.Error("nested WithTx got a different repository than the outer transaction")
+			}
+
+			chars, err := inner.GetCharacters(ctx, testBlizzardID)
+			if err != nil {
+				t.Fatalf("GetCharacters: %v", err)
+			}
+			if len(chars) != 1 {
+				t.Errorf("nested WithTx sees %d characters, want the outer write", len(chars))
+			}
+
+			return inner.SaveSyncState(ctx, testBlizzardID, 1)
+		}); err != nil {
+			t.Fatalf("nested WithTx: %v", err)
+		}
+
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("WithTx error = %v, want %v", err, errAbort)
+	}
+
+	if n := countRows(t, repo, "profile"); n != 0 {
+		t.Errorf("got %d characters, want the nested commit rolled back with the outer one", n)
+	}
+	if n := countRows(t, repo, "profile_sync"); n != 0 {
+		t.Errorf("got %d sync states, want the nested write rolled back with the outer one", n)
+	}
+}
+
+func TestWithTxCommitsAllWrites(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+	char := testCharacter(1, "Jaina")
+
+	err := repo.WithTx(ctx, func(tx PostgresRepository) error {
+		if err := tx.SaveCharacters(ctx, []entity.Character{char}); err != nil {
+			return err
+		}
+		if err := tx.SaveCharacterSnapshots(ctx, entity.ChangedSnapshots(nil, []entity.Character{char})); err != nil {
+			return err
+		}
+		if err := tx.SaveGuilds(ctx, []entity.Guild{{
+			CharacterID: char.CharacterID,
+			GuildID:     1,
+			Name:        "Test Guild",
+			NameSlug:    "test-guild",
+			Realm:       "Silvermoon",
+			RealmSlug:   "silvermoon",
+			Faction:     "Alliance",
+		}}); err != nil {
+			return err
+		}
+		return tx.SaveSyncState(ctx, testBlizzardID, 1)
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+
+	for table, want := range map[string]int{"profile": 1, "character_snapshot": 1, "guild": 1, "profile_sync": 1} {
+		if n := countRows(t, repo, table); n != want {
+			t.Errorf("%s has %d rows, want %d", table, n, want)
+		}
+	}
+}
+
+func TestReaderRoutesPrimaryContextAwayFromReplica(t *testing.T) {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	ctx := context.Background()
+
+	pool := dbtest.Pool(t)
+
+	cfg := &config.Config{}
+	cfg.DB.MaxConns = 2
+	cfg.DB.ConnectTimeout = 5 * time.Second
+	replica, err := dbpool.NewReplica(ctx, pool.Config().ConnString(), cfg, log)
+	if err != nil {
+		t.Fatalf("NewReplica: %v", err)
+	}
+	t.Cleanup(replica.Close)
+
+	repo := NewPostgresRepository(pool, replica, log)
+
+	if got := repo.reader(ctx); got != querier(replica.Raw()) {
+		t.Error("plain read did not go to the healthy replica")
+	}
+	if got := repo.reader(dbpool.WithPrimary(ctx)); got != querier(pool) {
+		t.Error("read marked WithPrimary did not go to the primary")
+	}
+
+	if err := repo.WithTx(ctx, func(tx PostgresRepository) error {
+		txRepo := tx.(*postgresRepository)
+		if got := txRepo.reader(ctx); got != txRepo.db {
+			t.Error("read inside a transaction left the transaction")
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+}
+
+func TestRealmIndexLoadsFromStoredRealms(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	if err := repo.SaveRealms(ctx, []slug.Realm{
+		{ID: 1, Slug: "howling-fjord", Names: map[string]string{"en_GB": "Howling Fjord", "ru_RU": "Ревущий фьорд"}},
+		{ID: 2, Slug: "kelthuzad", Names: map[string]string{"en_US": "Kel'Thuzad"}},
+	}); err != nil {
+		t.Fatalf("SaveRealms: %v", err)
+	}
+	// A second refresh renames a realm in place instead of adding a row.
+	if err := repo.SaveRealms(ctx, []slug.Realm{
+		{ID: 2, Slug: "kelthuzad", Names: map[string]string{"en_US": "Kel'Thuzad", "de_DE": "Kel’Thuzad"}},
+	}); err != nil {
+		t.Fatalf("SaveRealms again: %v", err)
+	}
+
+	stored, err := repo.GetRealms(ctx)
+	if err != nil {
+		t.Fatalf("GetRealms: %v", err)
+	}
+	if len(stored) != 2 {
+		t.Fatalf("got %d stored realms, want 2", len(stored))
+	}
+
+	ri := slug.NewRealmIndex()
+	ri.Load(stored)
+
+	for _, name := range []string{"howling-fjord", "Ревущий фьорд", "Kel'Thuzad", "Kel’Thuzad"} {
+		if _, ok := ri.Resolve(name); !ok {
+			t.Errorf("Resolve(%q) missed a stored realm", name)
+		}
+	}
+	if r, ok := ri.Resolve("Silvermoon"); ok {
+		t.Errorf("Resolve(Silvermoon) = %+v, want a miss", r)
+	}
+	if got := ri.Slug("Silvermoon"); got != "silvermoon" {
+		t.Errorf("Slug of an unstored realm = %q, want silvermoon", got)
+	}
+}
+
+func TestGetMainsPicksMainOrBestVisibleScore(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	// Account 100 chose its lower-scored character as main.
+	jaina := testCharacter(1, "Jaina")
+	thrall := testCharacter(2, "Thrall")
+	thrall.MythicScore = 3100
+
+	// Account 200 has no main; its best character is hidden.
+	anduin := testCharacter(3, "Anduin")
+	anduin.BlizzardID, anduin.Battletag, anduin.MythicScore = "200", "Other#5678", 1800
+	varian := testCharacter(4, "Varian")
+	varian.BlizzardID, varian.Battletag, varian.MythicScore = "200", "Other#5678", 3300
+
+	// Account 300 hid the character it chose as main.
+	sylvanas := testCharacter(5, "Sylvanas")
+	sylvanas.BlizzardID, sylvanas.Battletag = "300", "Third#9012"
+
+	if err := repo.SaveCharacters(ctx, []entity.Character{jaina, thrall, anduin, varian, sylvanas}); err != nil {
+		t.Fatalf("SaveCharacters: %v", err)
+	}
+	if err := repo.SetMainCharacter(ctx, testBlizzardID, "", "Jaina"); err != nil {
+		t.Fatalf("SetMainCharacter: %v", err)
+	}
+	if err := repo.SavePreference(ctx, "200", "", "Varian", entity.Preference{Hidden: true}); err != nil {
+		t.Fatalf("SavePreference: %v", err)
+	}
+	if err := repo.SetMainCharacter(ctx, "300", "", "Sylvanas"); err != nil {
+		t.Fatalf("SetMainCharacter: %v", err)
+	}
+	if err := repo.SavePreference(ctx, "300", "", "Sylvanas", entity.Preference{Hidden: true}); err != nil {
+		t.Fatalf("SavePreference: %v", err)
+	}
+
+	mains, err := repo.GetMains(ctx, []string{testBlizzardID, "999"}, []string{"other#5678", "third#9012", "nobody#0000"})
+	if err != nil {
+		t.Fatalf("GetMains: %v", err)
+	}
+
+	got := make(map[string]entity.Character, len(mains))
+	for _, c := range mains {
+		if _, dup := got[c.BlizzardID]; dup {
+			t.Errorf("account %s returned twice", c.BlizzardID)
+		}
+		got[c.BlizzardID] = c
+	}
+	if len(got) != 3 {
+		t.Fatalf("got mains for %d accounts, want 3", len(got))
+	}
+
+	if c := got[testBlizzardID]; c.Name != "Jaina" || !c.IsMain {
+		t.Errorf("account 100 main = %s (is_main %v), want Jaina", c.Name, c.IsMain)
+	}
+	if c := got["200"]; c.Name != "Anduin" || c.IsMain {
+		t.Errorf("account 200 main = %s (is_main %v), want visible best score Anduin", c.Name, c.IsMain)
+	}
+	if c := got["300"]; c.Name != "Sylvanas" || !c.IsMain {
+		t.Errorf("account 300 main = %s (is_main %v), want hidden main Sylvanas", c.Name, c.IsMain)
+	}
+}
+
+func TestGetAccountSummary(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	jaina := testCharacter(1, "Jaina")
+	thrall := testCharacter(2, "Thrall")
+	thrall.MythicScore, thrall.Ilvl = 3100, 630
+	lowbie := testCharacter(3, "Anduin")
+	lowbie.Lvl, lowbie.Ilvl, lowbie.MythicScore = 40, 300, 0
+	hidden := testCharacter(4, "Varian")
+	hidden.MythicScore = 3500
+
+	if err := repo.SaveCharacters(ctx, []entity.Character{jaina, thrall, lowbie, hidden}); err != nil {
+		t.Fatalf("SaveCharacters: %v", err)
+	}
+	guild := func(characterID int) entity.Guild {
+		return entity.Guild{
+			CharacterID: characterID, GuildID: 10, Name: "Test Guild", NameSlug: "test-guild",
+			Realm: "Silvermoon", RealmSlug: "silvermoon", Faction: "Alliance",
+		}
+	}
+	if err := repo.SaveGuilds(ctx, []entity.Guild{guild(1), guild(2)}); err != nil {
+		t.Fatalf("SaveGuilds: %v", err)
+	}
+	if err := repo.SetMainCharacter(ctx, testBlizzardID, "", "Jaina"); err != nil {
+		t.Fatalf("SetMainCharacter: %v", err)
+	}
+	if err := repo.SavePreference(ctx, testBlizzardID, "", "Varian", entity.Preference{Hidden: true}); err != nil {
+		t.Fatalf("SavePreference: %v", err)
+	}
+
+	summary, err := repo.GetAccountSummary(ctx, testBlizzardID, 80)
+	if err != nil {
+		t.Fatalf("GetAccountSummary: %v", err)
+	}
+
+	if summary.Main == nil || summary.Main.Name != "Jaina" {
+		t.Fatalf("main = %+v, want Jaina", summary.Main)
+	}
+	if len(summary.Alts) != 2 || summary.Alts[0].Name != "Thrall" || summary.Alts[1].Name != "Anduin" {
+		t.Errorf("alts = %+v, want Thrall then Anduin without the hidden Varian", summary.Alts)
+	}
+	if summary.MaxLevelAlts != 1 {
+		t.Errorf("max level alts = %d, want 1", summary.MaxLevelAlts)
+	}
+	if summary.HighestMythicScore != 3100 {
+		t.Errorf("highest mythic score = %v, want 3100 from visible characters", summary.HighestMythicScore)
+	}
+	if summary.AverageIlvl != 625 {
+		t.Errorf("average ilvl = %v, want 625 over max-level characters", summary.AverageIlvl)
+	}
+	if len(summary.Guilds) != 1 || summary.Guilds[0].GuildID != 10 {
+		t.Errorf("guilds = %+v, want the shared guild once", summary.Guilds)
+	}
+	if summary.Battletag != "Tester#1234" {
+		t.Errorf("battletag = %q", summary.Battletag)
+	}
+
+	if _, err := repo.GetAccountSummary(ctx, "999", 80); err == nil {
+		t.Error("summary of an unknown account succeeded")
+	}
+}
+
+func TestGetGuildLeaderboardPagesThroughTies(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	// Equal scores and item levels force the character_id tiebreak.
+	stats := []struct {
+		score float64
+		ilvl  int
+		spec  string
+	}{
+		{3000, 630, "Frost"},
+		{2500, 620, "Protection"},
+		{2500, 625, "Holy"},
+		{2500, 620, "Fire"},
+		{1000, 600, "Arcane"},
+	}
+	chars := make([]entity.Character, 0, len(stats))
+	guilds := make([]entity.Guild, 0, len(stats))
+	for i, s := range stats {
+		c := testCharacter(i+1, fmt.Sprintf("Member%d", i+1))
+		c.MythicScore, c.Ilvl, c.Spec = s.score, s.ilvl, s.spec
+		chars = append(chars, c)
+		guilds = append(guilds, entity.Guild{
+			CharacterID: c.CharacterID, GuildID: 10, Name: "Test Guild", NameSlug: "test-guild",
+			Realm: "Silvermoon", RealmSlug: "silvermoon", Faction: "Alliance",
+		})
+	}
+	if err := repo.SaveCharacters(ctx, chars); err != nil {
+		t.Fatalf("SaveCharacters: %v", err)
+	}
+	if err := repo.SaveGuilds(ctx, guilds); err != nil {
+		t.Fatalf("SaveGuilds: %v", err)
+	}
+
+	walk := func(filter entity.LeaderboardFilter) []int {
+		t.Helper()
+
+		var ids []int
+		for pages := 0; ; pages++ {
+			if pages > len(stats) {
+				t.Fatalf("leaderboard did not end after %d pages", pages)
+			}
+			page, err := repo.GetGuildLeaderboard(ctx, filter)
+			if err != nil {
+				t.Fatalf("GetGuildLeaderboard: %v", err)
+			}
+			if len(page.Entries) > filter.Limit {
+				t.Fatalf("page has %d entries, limit %d", len(page.Entries), filter.Limit)
+			}
+			for _, c := range page.Entries {
+				ids = append(ids, c.CharacterID)
+			}
+			if page.NextCursor == "" {
+				return ids
+			}
+			if filter.After, err = entity.DecodeLeaderboardCursor(page.NextCursor); err != nil {
+				t.Fatalf("decode next cursor: %v", err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter entity.LeaderboardFilter
+		want   []int
+	}{
+		{name: "mythic score", filter: entity.LeaderboardFilter{SortBy: entity.LeaderboardByMythicScore}, want: []int{1, 4, 3, 2, 5}},
+		{name: "item level", filter: entity.LeaderboardFilter{SortBy: entity.LeaderboardByIlvl}, want: []int{1, 3, 4, 2, 5}},
+		{name: "damage only", filter: entity.LeaderboardFilter{SortBy: entity.LeaderboardByMythicScore, Role: entity.RoleDamage}, want: []int{1, 4, 5}},
+		{name: "tanks only", filter: entity.LeaderboardFilter{SortBy: entity.LeaderboardByMythicScore, Role: entity.RoleTank}, want: []int{2}},
+		{name: "class", filter: entity.LeaderboardFilter{SortBy: entity.LeaderboardByMythicScore, Class: "MAGE"}, want: []int{1, 4, 3, 2, 5}},
+		{name: "wildcard class", filter: entity.LeaderboardFilter{SortBy: entity.LeaderboardByMythicScore, Class: "%"}},
+		{name: "wildcard spec", filter: entity.LeaderboardFilter{SortBy: entity.LeaderboardByMythicScore, Spec: "_rost"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, limit := range []int{1, 2, 10} {
+				filter := tt.filter
+				filter.GuildID, filter.Limit = 10, limit
+				if got := walk(filter); !slices.Equal(got, tt.want) {
+					t.Errorf("limit %d: order = %v, want %v", limit, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestIlikeEqualEscapesWildcards(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Mage", "Mage"},
+		{"%", `\%`},
+		{"Death_Knight", `Death\_Knight`},
+		{`50\50%`, `50\\50\%`},
+	}
+
+	for _, tt := range tests {
+		sql, args, err := ilikeEqual("p.class", tt.in).ToSql()
+		if err != nil {
+			t.Fatalf("ToSql: %v", err)
+		}
+		if sql != "p.class ILIKE ?" || len(args) != 1 || args[0] != tt.want {
+			t.Errorf("ilikeEqual(%q) = %s %v, want the pattern %q", tt.in, sql, args, tt.want)
+		}
+	}
+}
+
+func TestSearchTreatsWildcardsLiterally(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	jaina := testCharacter(1, "Jaina")
+	thrall := testCharacter(2, "Thrall")
+	thrall.Class = "Shaman"
+	if err := repo.SaveCharacters(ctx, []entity.Character{jaina, thrall}); err != nil {
+		t.Fatalf("SaveCharacters: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		filter entity.SearchFilter
+		want   []string
+	}{
+		{name: "substring", filter: entity.SearchFilter{Query: "ain"}, want: []string{"Jaina"}},
+		{name: "percent query", filter: entity.SearchFilter{Query: "%%"}},
+		{name: "underscore query", filter: entity.SearchFilter{Query: "_a_"}},
+		{name: "class filter", filter: entity.SearchFilter{Query: "thrall", Class: "shaman"}, want: []string{"Thrall"}},
+		{name: "wildcard class filter", filter: entity.SearchFilter{Query: "thrall", Class: "%"}},
+		{name: "wildcard guild filter", filter: entity.SearchFilter{Query: "jaina", Guild: "%"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter
+			filter.Limit = 10
+			result, err := repo.Search(ctx, filter)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+
+			got := make([]string, 0, len(result.Characters))
+			for _, m := range result.Characters {
+				got = append(got, m.Character.Name)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && !slices.Equal(got, tt.want)) {
+				t.Errorf("matched %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPValidatorsRoundTrip(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	const (
+		details = "https://eu.api.blizzard.com/profile/wow/character/silvermoon/jaina"
+		mythic  = "https://eu.api.blizzard.com/profile/wow/character/silvermoon/jaina/mythic-keystone-profile"
+	)
+	if err := repo.SaveHTTPValidators(ctx, []entity.HTTPValidator{
+		{URL: details, ETag: `"v1"`, LastModified: "Sun, 18 Oct 2026 10:00:00 GMT"},
+		{URL: mythic, ETag: `"m1"`},
+	}); err != nil {
+		t.Fatalf("SaveHTTPValidators: %v", err)
+	}
+	// A later response replaces the cached validators of its URL.
+	if err := repo.SaveHTTPValidators(ctx, []entity.HTTPValidator{{URL: details, ETag: `"v2"`}}); err != nil {
+		t.Fatalf("SaveHTTPValidators again: %v", err)
+	}
+
+	got, err := repo.GetHTTPValidators(ctx, []string{details, mythic, "https://eu.api.blizzard.com/unknown"})
+	if err != nil {
+		t.Fatalf("GetHTTPValidators: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d validators, want 2: %+v", len(got), got)
+	}
+	if v := got[details]; v.ETag != `"v2"` || v.LastModified != "" {
+		t.Errorf("details validator = %+v, want the replaced one", v)
+	}
+	if v := got[mythic]; v.ETag != `"m1"` {
+		t.Errorf("mythic validator = %+v", v)
+	}
+}
+
+func TestExportCharacters(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	thrall := testCharacter(2, "Thrall")
+	jaina := testCharacter(1, "Jaina")
+	anduin := testCharacter(3, "Anduin")
+	anduin.BlizzardID, anduin.Battletag = "050", "Other#5678"
+	if err := repo.SaveCharacters(ctx, []entity.Character{thrall, jaina, anduin}); err != nil {
+		t.Fatalf("SaveCharacters: %v", err)
+	}
+
+	export := func(blizzardID string) []string {
+		t.Helper()
+		var names []string
+		err := repo.ExportCharacters(ctx, blizzardID, func(char entity.Character) error {
+			names = append(names, char.BlizzardID+"/"+char.Name)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("ExportCharacters(%q): %v", blizzardID, err)
+		}
+		return names
+	}
+
+	if got, want := export(""), []string{"050/Anduin", "100/Jaina", "100/Thrall"}; !slices.Equal(got, want) {
+		t.Errorf("export all = %v, want %v", got, want)
+	}
+	if got, want := export(testBlizzardID), []string{"100/Jaina", "100/Thrall"}; !slices.Equal(got, want) {
+		t.Errorf("export account = %v, want %v", got, want)
+	}
+	if got := export("999"); len(got) != 0 {
+		t.Errorf("export unknown account = %v", got)
+	}
+
+	// A failing writer stops the export and its error comes back unchanged.
+	errWrite := errors.New("disk full")
+	calls := 0
+	err := repo.ExportCharacters(ctx, "", func(entity.Character) error {
+		calls++
+		return errWrite
+	})
+	if !errors.Is(err, errWrite) || calls != 1 {
+		t.Errorf("ExportCharacters = %v after %d calls, want %v after 1", err, calls, errWrite)
+	}
+}
@@ -0,0 +1,39 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"profile-service/internal/entity"
+	"profile-service/pkg/errors"
+)
+
+// Broker is the slot for a message bus client. A NATS connection or Kafka
+// producer only needs a thin wrapper with this method to be plugged in.
+type Broker interface {
+	Publish(ctx context.Context, subject string, data []byte) error
+}
+
+type brokerPublisher struct {
+	broker Broker
+	prefix string
+}
+
+// NewBrokerPublisher publishes each event to "<prefix>.<type>.v<version>",
+// e.g. "profile.profile.refreshed.v1", so consumers can subscribe per schema.
+func NewBrokerPublisher(broker Broker, prefix string) *brokerPublisher {
+	return &brokerPublisher{broker: broker, prefix: prefix}
+}
+
+func (bp *brokerPublisher) Publish(ctx context.Context, event entity.DomainEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return errors.NewAppError("failed marshal domain event", err)
+	}
+
+	subject := fmt.Sprintf("%s.%s.v%d", bp.prefix, event.Type, event.Version)
+	if err := bp.broker.Publish(ctx, subject, data); err != nil {
+		return errors.NewAppError("failed publish domain event", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"profile-service/internal/entity"
+	"testing"
+)
+
+type fakeBroker struct {
+	subjects []string
+	messages [][]byte
+	err      error
+}
+
+func (fb *fakeBroker) Publish(_ context.Context, subject string, data []byte) error {
+	if fb.err != nil {
+		return fb.err
+	}
+	fb.subjects = append(fb.subjects, subject)
+	fb.messages = append(fb.messages, data)
+	return nil
+}
+
+func TestBrokerPublisherPublishesVersionedSubject(t *testing.T) {
+	broker := &fakeBroker{}
+	event, err := entity.NewDomainEvent(entity.EventProfileRefreshed, entity.ProfileRefreshedVersion, entity.ProfileRefreshedV1{
+		BlizzardID:   "100",
+		CharacterIDs: []int{1, 2},
+	})
+	if err != nil {
+		t.Fatalf("NewDomainEvent: %v", err)
+	}
+
+	if err := NewBrokerPublisher(broker, "profile").Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	if len(broker.subjects) != 1 || broker.subjects[0] != "profile.profile.refreshed.v1" {
+		t.Fatalf("subjects = %v, want profile.profile.refreshed.v1", broker.subjects)
+	}
+
+	var got entity.DomainEvent
+	if err := json.Unmarshal(broker.messages[0], &got); err != nil {
+		t.Fatalf("decode message: %v", err)
+	}
+	if got.ID != event.ID || got.Type != event.Type || got.Version != event.Version || got.Source != entity.DomainEventSource {
+		t.Errorf("message = %+v, want the envelope of %+v", got, event)
+	}
+
+	var data entity.ProfileRefreshedV1
+	if err := json.Unmarshal(got.Data, &data); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	if data.BlizzardID != "100" || len(data.CharacterIDs) != 2 {
+		t.Errorf("data = %+v, want the published payload", data)
+	}
+}
+
+func TestBrokerPublisherWrapsBrokerError(t *testing.T) {
+	errDown := errors.New("broker down")
+	event, err := entity.NewDomainEvent(entity.EventMainChanged, entity.MainChangedVersion, entity.MainChangedV1{BlizzardID: "100"})
+	if err != nil {
+		t.Fatalf("NewDomainEvent: %v", err)
+	}
+
+	err = NewBrokerPublisher(&fakeBroker{err: errDown}, "profile").Publish(context.Background(), event)
+	if !errors.Is(err, errDown) {
+		t.Fatalf("Publish error = %v, want it to wrap the broker error", err)
+	}
+}
@@ -0,0 +1,14 @@
+package events
+
+import (
+	"context"
+	"profile-service/internal/entity"
+)
+
+// EventPublisher hands domain events to other services. The usecase stores
+// events in domain_event with the change and the relay calls Publish for each
+// of them afterwards; an event whose Publish fails is retried on the next
+// tick, so delivery is at least once.
+type EventPublisher interface {
+	Publish(ctx context.Context, event entity.DomainEvent) error
+}
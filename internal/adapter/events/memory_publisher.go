@@ -0,0 +1,48 @@
+package events
+
+import (
+	"context"
+	"profile-service/internal/entity"
+	"sync"
+)
+
+// memoryPublisher keeps published events in memory, for tests and local runs.
+type memoryPublisher struct {
+	mu     sync.Mutex
+	events []entity.DomainEvent
+}
+
+func NewMemoryPublisher() *memoryPublisher {
+	return &memoryPublisher{}
+}
+
+func (mp *memoryPublisher) Publish(_ context.Context, event entity.DomainEvent) error {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	mp.events = append(mp.events, event)
+	return nil
+}
+
+// Events returns a copy of everything published so far, oldest first.
+func (mp *memoryPublisher) Events() []entity.DomainEvent {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	return append([]entity.DomainEvent(nil), mp.events...)
+}
+
+func (mp *memoryPublisher) Reset() {
+	mp.mu.Lock()
+	defer mp.mu.Unlock()
+	mp.events = nil
+}
+
+type nopPublisher struct{}
+
+// NewNopPublisher drops every event.
+func NewNopPublisher() EventPublisher {
+	return nopPublisher{}
+}
+
+func (nopPublisher) Publish(context.Context, entity.DomainEvent) error {
+	return nil
+}
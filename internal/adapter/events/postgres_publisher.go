@@ -0,0 +1,51 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"profile-service/internal/entity"
+	"profile-service/pkg/errors"
+	logger "profile-service/pkg/log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// eventRef is the NOTIFY payload. It only carries a reference: payloads are
+// capped at 8000 bytes, and listeners load the event from domain_event, where
+// the usecase stored it together with the change.
+type eventRef struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+}
+
+type postgresPublisher struct {
+	pool    *pgxpool.Pool
+	channel string
+	log     *logrus.Logger
+}
+
+// NewPostgresPublisher announces events already stored in domain_event on
+// channel with NOTIFY.
+func NewPostgresPublisher(pool *pgxpool.Pool, channel string, log *logrus.Logger) *postgresPublisher {
+	return &postgresPublisher{pool: pool, channel: channel, log: log}
+}
+
+func (pp *postgresPublisher) Publish(ctx context.Context, event entity.DomainEvent) error {
+	ref, err := json.Marshal(eventRef{ID: event.ID, Type: event.Type, Version: event.Version})
+	if err != nil {
+		return errors.NewAppError("failed marshal event reference", err)
+	}
+
+	if _, err := pp.pool.Exec(ctx, "SELECT pg_notify($1, $2)", pp.channel, string(ref)); err != nil {
+		pp.logFor(ctx).WithError(err).WithField("event", event.Type).Error("failed to notify domain event")
+		return errors.NewAppError("failed to notify domain event", err)
+	}
+
+	return nil
+}
+
+func (pp *postgresPublisher) logFor(ctx context.Context) *logrus.Entry {
+	return logger.FromContext(ctx, pp.log)
+}
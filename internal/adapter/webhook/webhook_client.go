@@ -0,0 +1,12 @@
+package webhook
+
+import (
+	"context"
+	"profile-service/internal/entity"
+)
+
+type WebhookClient interface {
+	// Deliver posts one signed delivery and returns the receiver's status
+	// code; any non-2xx answer is reported as an error too.
+	Deliver(ctx context.Context, d entity.WebhookDelivery) (int, error)
+}
@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"profile-service/internal/entity"
+	"profile-service/pkg/config"
+	"profile-service/pkg/errors"
+	"profile-service/pkg/metrics"
+	"strconv"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+const (
+	HeaderID        = "X-Webhook-Id"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+type webhookClient struct {
+	client *http.Client
+}
+
+// NewWebhookClient builds the delivery client. Unless allow_private is set,
+// connections to loopback, private and link-local addresses are refused at
+// dial time, so a subscription URL cannot reach internal services.
+func NewWebhookClient(cfg *config.Config, m *metrics.Metrics) *webhookClient {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !cfg.Webhooks.AllowPrivate {
+		dialer.Control = refusePrivate
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	traced := otelhttp.NewTransport(transport, otelhttp.WithSpanNameFormatter(func(_ string, _ *http.Request) string {
+		return "webhook deliver"
+	}))
+
+	return &webhookClient{
+		client: &http.Client{
+			Timeout:   cfg.Webhooks.Timeout,
+			Transport: m.Transport(traced, "webhook", func(*http.Request) string { return "deliver" }),
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (wc *webhookClient) Deliver(ctx context.Context, d entity.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(entity.OutboxEvent{
+		ID:        d.ID,
+		Type:      d.EventType,
+		Payload:   d.Payload,
+		CreatedAt: d.CreatedAt,
+	})
+	if err != nil {
+		return 0, errors.NewAppError("failed marshal webhook body", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.NewAppError("failed create webhook request", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "profile-service-webhooks")
+	req.Header.Set(HeaderID, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(d.Secret, timestamp, body))
+
+	resp, err := wc.client.Do(req)
+	if err != nil {
+		return 0, errors.NewAppError("webhook request failed", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, errors.NewHTTPError(resp.StatusCode, "webhook receiver rejected delivery", nil)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns the X-Webhook-Signature value: "sha256=" and the hex HMAC of
+// "<timestamp>.<body>". Receivers recompute it with their secret and should
+// reject stale timestamps to block replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func refusePrivate(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("webhook address %s is not an ip", host)
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() {
+		return fmt.Errorf("webhook address %s is not public", ip)
+	}
+	return nil
+}
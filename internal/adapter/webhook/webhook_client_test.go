@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"profile-service/internal/entity"
+	"profile-service/pkg/config"
+	"profile-service/pkg/metrics"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	// Computed independently: HMAC-SHA256("whsec_test", `1700000000.{"id":1}`).
+	const want = "sha256=2f441ba4b3b2d50d28a9ab9d9fd8880376ecd1eb5d0435401553f5d8d0a5dcf8"
+
+	if got := Sign("whsec_test", 1700000000, []byte(`{"id":1}`)); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+	if got := Sign("other", 1700000000, []byte(`{"id":1}`)); got == want {
+		t.Error("Sign ignores the secret")
+	}
+	if got := Sign("whsec_test", 1700000001, []byte(`{"id":1}`)); got == want {
+		t.Error("Sign ignores the timestamp")
+	}
+}
+
+func TestRefusePrivate(t *testing.T) {
+	tests := []struct {
+		address string
+		refused bool
+	}{
+		{"127.0.0.1:443", true},
+		{"[::1]:443", true},
+		{"10.1.2.3:443", true},
+		{"172.16.0.1:443", true},
+		{"172.31.255.255:443", true},
+		{"192.168.1.10:443", true},
+		{"169.254.169.254:80", true},
+		{"[fe80::1]:443", true},
+		{"[fc00::1]:443", true},
+		{"0.0.0.0:443", true},
+		{"224.0.0.1:443", true},
+		{"example.com:443", true},
+		{"93.184.216.34:443", false},
+		{"172.32.0.1:443", false},
+		{"[2606:4700:4700::1111]:443", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := refusePrivate("tcp", tt.address, nil)
+			if tt.refused && err == nil {
+				t.Errorf("%s was allowed, want it refused", tt.address)
+			}
+			if !tt.refused && err != nil {
+				t.Errorf("%s was refused: %v", tt.address, err)
+			}
+		})
+	}
+}
+
+func testConfig(allowPrivate bool) *config.Config {
+	cfg := &config.Config{}
+	cfg.Webhooks.Timeout = 5 * time.Second
+	cfg.Webhooks.AllowPrivate = allowPrivate
+	return cfg
+}
+
+func TestDeliverRefusesLoopbackReceiver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached a loopback receiver")
+	}))
+	defer srv.Close()
+
+	code, err := NewWebhookClient(testConfig(false), metrics.New()).Deliver(context.Background(), entity.WebhookDelivery{
+		ID:        1,
+		EventType: entity.WebhookCharacterLevelUp,
+		URL:       srv.URL,
+		Secret:    "whsec_test",
+	})
+	if err == nil {
+		t.Fatal("Deliver succeeded, want the dial refused")
+	}
+	if code != 0 {
+		t.Errorf("code = %d, want 0", code)
+	}
+}
+
+func TestDeliverSignsTheBody(t *testing.T) {
+	const secret = "whsec_test"
+
+	var received entity.OutboxEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body: %v", err)
+		}
+		timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		if err != nil {
+			t.Errorf("timestamp header: %v", err)
+		}
+		if got, want := r.Header.Get(HeaderSignature), Sign(secret, timestamp, body); got != want {
+			t.Errorf("signature = %s, want %s", got, want)
+		}
+		if got := r.Header.Get(HeaderEvent); got != entity.WebhookCharacterLevelUp {
+			t.Errorf("event header = %q", got)
+		}
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	code, err := NewWebhookClient(testConfig(true), metrics.New()).Deliver(context.Background(), entity.WebhookDelivery{
+		ID:        7,
+		EventType: entity.WebhookCharacterLevelUp,
+		Payload:   json.RawMessage(`{"character_id":1}`),
+		URL:       srv.URL,
+		Secret:    secret,
+	})
+	if err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if code != http.StatusNoContent {
+		t.Errorf("code = %d, want 204", code)
+	}
+	if received.ID != 7 || string(received.Payload) != `{"character_id":1}` {
+		t.Errorf("received %+v", received)
+	}
+}
@@ -6,6 +6,7 @@ type Character struct {
 	Battletag   string  `json:"battletag" db:"battletag"`
 	Name        string  `json:"name" db:"name"`
 	Realm       string  `json:"realm" db:"realm"`
+	RealmSlug   string  `json:"realm_slug" db:"realm_slug"`
 	Race        string  `json:"race" db:"race"`
 	Faction     string  `json:"faction" db:"faction"`
 	Class       string  `json:"class" db:"class"`
@@ -15,4 +16,5 @@ type Character struct {
 	Guild       string  `json:"guild" db:"guild"`
 	MythicScore float64 `json:"mythic_score" db:"mythic_score"`
 	IsMain      bool    `json:"is_main" db:"is_main"`
+	Preference
 }
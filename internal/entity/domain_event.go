@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+const DomainEventSource = "profile-service"
+
+// Event types and their current schema versions. A version is bumped only for
+// breaking payload changes; adding optional fields keeps it.
+const (
+	EventProfileRefreshed   = "profile.refreshed"
+	EventMainChanged        = "profile.main_changed"
+	EventGuildRosterUpdated = "guild.roster_updated"
+
+	ProfileRefreshedVersion   = 1
+	MainChangedVersion        = 1
+	GuildRosterUpdatedVersion = 1
+)
+
+// DomainEvent is the envelope every publisher emits. Consumers dispatch on
+// Type and Version and decode Data with the matching schema.
+type DomainEvent struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	Source     string          `json:"source"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+type ProfileRefreshedV1 struct {
+	BlizzardID   string `json:"blizzard_id"`
+	CharacterIDs []int  `json:"character_ids"`
+	Unchanged    int    `json:"unchanged"`
+}
+
+type MainChangedV1 struct {
+	BlizzardID          string `json:"blizzard_id"`
+	CharacterID         int    `json:"character_id"`
+	Name                string `json:"name"`
+	Realm               string `json:"realm"`
+	PreviousCharacterID int    `json:"previous_character_id,omitempty"`
+}
+
+type GuildRosterUpdatedV1 struct {
+	GuildID      int    `json:"guild_id"`
+	NameSlug     string `json:"name_slug"`
+	RealmSlug    string `json:"realm_slug"`
+	Members      int    `json:"members"`
+	Joined       []int  `json:"joined"`
+	Left         []int  `json:"left"`
+	RankChanged  []int  `json:"rank_changed"`
+	BaselineSync bool   `json:"baseline_sync"`
+}
+
+func NewDomainEvent(eventType string, version int, data any) (DomainEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return DomainEvent{}, err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return DomainEvent{}, err
+	}
+
+	return DomainEvent{
+		ID:         hex.EncodeToString(id),
+		Type:       eventType,
+		Version:    version,
+		Source:     DomainEventSource,
+		OccurredAt: time.Now().UTC(),
+		Data:       payload,
+	}, nil
+}
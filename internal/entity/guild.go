@@ -9,3 +9,23 @@ type Guild struct {
 	RealmSlug   string `json:"realm_slug" db:"realm_slug"`
 	Faction     string `json:"faction" db:"faction"`
 }
+
+type GuildMember struct {
+	GuildID     int    `json:"guild_id" db:"guild_id"`
+	CharacterID int    `json:"character_id" db:"character_id"`
+	Name        string `json:"name" db:"name"`
+	RealmSlug   string `json:"realm_slug" db:"realm_slug"`
+	Level       int    `json:"level" db:"level"`
+	ClassID     int    `json:"class_id" db:"class_id"`
+	Rank        int    `json:"rank" db:"rank"`
+}
+
+type GuildRoster struct {
+	GuildID   int           `json:"guild_id"`
+	Name      string        `json:"name"`
+	NameSlug  string        `json:"name_slug"`
+	Realm     string        `json:"realm"`
+	RealmSlug string        `json:"realm_slug"`
+	Faction   string        `json:"faction"`
+	Members   []GuildMember `json:"members"`
+}
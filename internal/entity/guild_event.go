@@ -0,0 +1,186 @@
+package entity
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	GuildEventJoin       = "join"
+	GuildEventLeave      = "leave"
+	GuildEventRankChange = "rank_change"
+)
+
+type GuildEvent struct {
+	ID          int64     `json:"id" db:"id"`
+	GuildID     int       `json:"guild_id" db:"guild_id"`
+	CharacterID int       `json:"character_id" db:"character_id"`
+	Name        string    `json:"name" db:"name"`
+	RealmSlug   string    `json:"realm_slug" db:"realm_slug"`
+	Type        string    `json:"type" db:"type"`
+	OldRank     *int      `json:"old_rank,omitempty" db:"old_rank"`
+	NewRank     *int      `json:"new_rank,omitempty" db:"new_rank"`
+	OccurredAt  time.Time `json:"occurred_at" db:"occurred_at"`
+}
+
+type GuildEventFilter struct {
+	GuildID int
+	Type    string
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+	After   *GuildEventCursor
+}
+
+type GuildEventCursor struct {
+	OccurredAt time.Time
+	ID         int64
+}
+
+type GuildEventPage struct {
+	GuildID    int          `json:"guild_id"`
+	Events     []GuildEvent `json:"events"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+func IsGuildEventType(t string) bool {
+	switch t {
+	case GuildEventJoin, GuildEventLeave, GuildEventRankChange:
+		return true
+	}
+	return false
+}
+
+// DiffGuildRoster compares the stored roster with a fresh one and returns
+// the joins, leaves and rank changes between them, ordered by character id.
+func DiffGuildRoster(guildID int, stored, current []GuildMember, at time.Time) []GuildEvent {
+	before := make(map[int]GuildMember, len(stored))
+	for _, m := range stored {
+		before[m.CharacterID] = m
+	}
+
+	events := make([]GuildEvent, 0)
+	seen := make(map[int]bool, len(current))
+	for _, m := range current {
+		seen[m.CharacterID] = true
+		rank := m.Rank
+
+		old, ok := before[m.CharacterID]
+		switch {
+		case !ok:
+			events = append(events, newGuildEvent(guildID, m, GuildEventJoin, nil, &rank, at))
+		case old.Rank != m.Rank:
+			oldRank := old.Rank
+			events = append(events, newGuildEvent(guildID, m, GuildEventRankChange, &oldRank, &rank, at))
+		}
+	}
+
+	for _, m := range stored {
+		if seen[m.CharacterID] {
+			continue
+		}
+		rank := m.Rank
+		events = append(events, newGuildEvent(guildID, m, GuildEventLeave, &rank, nil, at))
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CharacterID < events[j].CharacterID
+	})
+	return events
+}
+
+// ApplyAccountMembers returns the stored roster of guildID updated with what a
+// refresh of one account saw: each refreshed character is a member exactly
+// when its fetched guild is guildID. Everyone else keeps the stored row. A
+// refresh does not see ranks, so a newly seen member gets the lowest rank on
+// the roster until a full roster sync reports the real one.
+func ApplyAccountMembers(guildID int, stored []GuildMember, chars []Character, guilds []Guild) []GuildMember {
+	inGuild := make(map[int]bool, len(guilds))
+	for _, g := range guilds {
+		if g.GuildID == guildID {
+			inGuild[g.CharacterID] = true
+		}
+	}
+	refreshed := make(map[int]Character, len(chars))
+	for _, c := range chars {
+		refreshed[c.CharacterID] = c
+	}
+
+	lowest := 0
+	current := make([]GuildMember, 0, len(stored)+len(inGuild))
+	seen := make(map[int]bool, len(stored))
+	for _, m := range stored {
+		lowest = max(lowest, m.Rank)
+		seen[m.CharacterID] = true
+
+		if c, ok := refreshed[m.CharacterID]; ok {
+			if !inGuild[m.CharacterID] {
+				continue
+			}
+			m.Name = c.Name
+			m.Level = c.Lvl
+		}
+		current = append(current, m)
+	}
+
+	for _, c := range chars {
+		if !inGuild[c.CharacterID] || seen[c.CharacterID] {
+			continue
+		}
+		current = append(current, GuildMember{
+			GuildID:     guildID,
+			CharacterID: c.CharacterID,
+			Name:        c.Name,
+			RealmSlug:   c.RealmSlug,
+			Level:       c.Lvl,
+			Rank:        lowest,
+		})
+	}
+
+	return current
+}
+
+func newGuildEvent(guildID int, m GuildMember, eventType string, oldRank, newRank *int, at time.Time) GuildEvent {
+	return GuildEvent{
+		GuildID:     guildID,
+		CharacterID: m.CharacterID,
+		Name:        m.Name,
+		RealmSlug:   m.RealmSlug,
+		Type:        eventType,
+		OldRank:     oldRank,
+		NewRank:     newRank,
+		OccurredAt:  at,
+	}
+}
+
+func (c GuildEventCursor) Encode() string {
+	raw := strconv.FormatInt(c.OccurredAt.UnixMicro(), 10) + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeGuildEventCursor(s string) (*GuildEventCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decode cursor: %w", err)
+	}
+
+	at, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+
+	micros, err := strconv.ParseInt(at, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse cursor time: %w", err)
+	}
+	eventID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse cursor event id: %w", err)
+	}
+
+	return &GuildEventCursor{OccurredAt: time.UnixMicro(micros).UTC(), ID: eventID}, nil
+}
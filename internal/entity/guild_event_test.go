@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApplyAccountMembers(t *testing.T) {
+	stored := []GuildMember{
+		{GuildID: 10, CharacterID: 1, Name: "Jaina", Level: 79, Rank: 2},
+		{GuildID: 10, CharacterID: 2, Name: "Thrall", Level: 80, Rank: 4},
+		{GuildID: 10, CharacterID: 50, Name: "Anduin", Level: 80, Rank: 6},
+	}
+	chars := []Character{
+		{CharacterID: 1, Name: "Jaina", RealmSlug: "silvermoon", Lvl: 80},
+		{CharacterID: 2, Name: "Thrall", RealmSlug: "silvermoon", Lvl: 80},
+		{CharacterID: 3, Name: "Sylvanas", RealmSlug: "silvermoon", Lvl: 80},
+	}
+	guilds := []Guild{
+		{CharacterID: 1, GuildID: 10},
+		{CharacterID: 3, GuildID: 10},
+	}
+
+	current := ApplyAccountMembers(10, stored, chars, guilds)
+	changes := DiffGuildRoster(10, stored, current, time.Now())
+
+	if len(changes) != 2 {
+		t.Fatalf("got %d changes, want Thrall leaving and Sylvanas joining: %+v", len(changes), changes)
+	}
+	if c := changes[0]; c.CharacterID != 2 || c.Type != GuildEventLeave {
+		t.Errorf("first change = %+v, want Thrall leaving", c)
+	}
+	if c := changes[1]; c.CharacterID != 3 || c.Type != GuildEventJoin || *c.NewRank != 6 {
+		t.Errorf("second change = %+v, want Sylvanas joining at the lowest rank", c)
+	}
+
+	for _, m := range current {
+		if m.CharacterID == 1 && (m.Level != 80 || m.Rank != 2) {
+			t.Errorf("Jaina = %+v, want the refreshed level and her stored rank", m)
+		}
+		if m.CharacterID == 50 && m != stored[2] {
+			t.Errorf("Anduin = %+v, want the untouched stored row", m)
+		}
+	}
+}
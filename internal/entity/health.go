@@ -0,0 +1,22 @@
+package entity
+
+const (
+	HealthUp       = "up"
+	HealthDown     = "down"
+	HealthDegraded = "degraded"
+)
+
+type DependencyStatus struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Critical  bool   `json:"critical"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Readiness is "down" when a critical dependency fails and "degraded" when
+// only optional ones (auth_service, Blizzard) are unreachable.
+type Readiness struct {
+	Status       string             `json:"status"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+}
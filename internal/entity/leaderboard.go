@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	LeaderboardByMythicScore = "mythic_score"
+	LeaderboardByIlvl        = "ilvl"
+)
+
+type LeaderboardFilter struct {
+	GuildID  int
+	SortBy   string
+	Class    string
+	Role     string
+	Spec     string
+	MinLevel int
+	Faction  string
+	Limit    int
+	After    *LeaderboardCursor
+}
+
+type LeaderboardCursor struct {
+	Value       float64
+	CharacterID int
+}
+
+type LeaderboardPage struct {
+	GuildID    int         `json:"guild_id"`
+	SortBy     string      `json:"sort_by"`
+	Entries    []Character `json:"entries"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+func (c LeaderboardCursor) Encode() string {
+	raw := strconv.FormatFloat(c.Value, 'f', -1, 64) + ":" + strconv.Itoa(c.CharacterID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeLeaderboardCursor(s string) (*LeaderboardCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decode cursor: %w", err)
+	}
+
+	value, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse cursor value: %w", err)
+	}
+	charID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("parse cursor character id: %w", err)
+	}
+
+	return &LeaderboardCursor{Value: v, CharacterID: charID}, nil
+}
@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestLeaderboardCursorRoundTrip(t *testing.T) {
+	for _, c := range []LeaderboardCursor{
+		{Value: 2450.5, CharacterID: 7},
+		{Value: 0, CharacterID: 1},
+		{Value: 3123.37, CharacterID: 123456789},
+		{Value: 639, CharacterID: 42},
+	} {
+		got, err := DecodeLeaderboardCursor(c.Encode())
+		if err != nil {
+			t.Fatalf("decode %+v: %v", c, err)
+		}
+		if *got != c {
+			t.Errorf("round trip of %+v = %+v", c, *got)
+		}
+	}
+}
+
+func TestDecodeLeaderboardCursorRejectsGarbage(t *testing.T) {
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	for name, cursor := range map[string]string{
+		"not base64":   "%%%",
+		"no separator": raw("2450.5"),
+		"bad value":    raw("high:7"),
+		"bad id":       raw("2450.5:seven"),
+		"empty":        "",
+	} {
+		if c, err := DecodeLeaderboardCursor(cursor); err == nil {
+			t.Errorf("%s: decoded %q to %+v, want an error", name, cursor, c)
+		}
+	}
+}
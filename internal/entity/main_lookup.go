@@ -0,0 +1,20 @@
+package entity
+
+const (
+	MainSourceMain      = "main"
+	MainSourceBestScore = "best_score"
+)
+
+// AccountMain is the character resolved for one requested Blizzard ID or
+// battletag. Source tells whether it is the chosen main or the visible
+// character with the highest M+ score.
+type AccountMain struct {
+	Query     string    `json:"query"`
+	Source    string    `json:"source"`
+	Character Character `json:"character"`
+}
+
+type MainLookupResult struct {
+	Mains   []AccountMain `json:"mains"`
+	Missing []string      `json:"missing"`
+}
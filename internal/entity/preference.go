@@ -0,0 +1,7 @@
+package entity
+
+type Preference struct {
+	Hidden       bool   `json:"hidden" db:"hidden"`
+	DisplayOrder *int   `json:"display_order,omitempty" db:"display_order"`
+	Note         string `json:"note" db:"note"`
+}
@@ -0,0 +1,15 @@
+package entity
+
+import "time"
+
+type Profile struct {
+	Characters []Character `json:"characters"`
+	SyncedAt   time.Time   `json:"synced_at"`
+	Degraded   bool        `json:"degraded"`
+}
+
+type SyncState struct {
+	BlizzardID string    `json:"blizzard_id" db:"blizzard_id"`
+	Characters int       `json:"characters" db:"characters"`
+	SyncedAt   time.Time `json:"synced_at" db:"synced_at"`
+}
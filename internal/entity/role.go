@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	RoleTank    = "tank"
+	RoleHealer  = "healer"
+	RoleDamage  = "damage"
+	RoleUnknown = "unknown"
+)
+
+var specRoles = map[string]string{
+	"защита":       RoleTank,
+	"кровь":        RoleTank,
+	"страж":        RoleTank,
+	"хмелевар":     RoleTank,
+	"месть":        RoleTank,
+	"свет":         RoleHealer,
+	"послушание":   RoleHealer,
+	"исцеление":    RoleHealer,
+	"ткач туманов": RoleHealer,
+	"сохранение":   RoleHealer,
+	"protection":   RoleTank,
+	"blood":        RoleTank,
+	"guardian":     RoleTank,
+	"brewmaster":   RoleTank,
+	"vengeance":    RoleTank,
+	"holy":         RoleHealer,
+	"discipline":   RoleHealer,
+	"restoration":  RoleHealer,
+	"mistweaver":   RoleHealer,
+	"preservation": RoleHealer,
+}
+
+var unknownSpecs = map[string]struct{}{
+	"":                  {},
+	"unknown":           {},
+	"нет специализации": {},
+}
+
+func SpecRole(spec string) string {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if _, ok := unknownSpecs[spec]; ok {
+		return RoleUnknown
+	}
+	if role, ok := specRoles[spec]; ok {
+		return role
+	}
+	return RoleDamage
+}
+
+// RoleSpecs returns the spec names that map to a tank or healer role. Damage
+// specs are everything else, so callers filter them by exclusion.
+func RoleSpecs(role string) []string {
+	specs := make([]string, 0)
+	for spec, r := range specRoles {
+		if r == role {
+			specs = append(specs, spec)
+		}
+	}
+	sort.Strings(specs)
+	return specs
+}
+
+// NonDamageSpecs returns every spec name that is not a damage spec, including
+// the placeholders used when Blizzard reports no specialization.
+func NonDamageSpecs() []string {
+	specs := make([]string, 0, len(specRoles)+len(unknownSpecs))
+	for spec := range specRoles {
+		specs = append(specs, spec)
+	}
+	for spec := range unknownSpecs {
+		specs = append(specs, spec)
+	}
+	sort.Strings(specs)
+	return specs
+}
@@ -0,0 +1,49 @@
+package entity
+
+import "testing"
+
+func TestSpecRole(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"Protection", RoleTank},
+		{"  blood ", RoleTank},
+		{"Хмелевар", RoleTank},
+		{"Restoration", RoleHealer},
+		{"Ткач туманов", RoleHealer},
+		{"Preservation", RoleHealer},
+		{"Frost", RoleDamage},
+		{"Огонь", RoleDamage},
+		{"", RoleUnknown},
+		{"Unknown", RoleUnknown},
+		{"Нет специализации", RoleUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := SpecRole(tt.spec); got != tt.want {
+			t.Errorf("SpecRole(%q) = %s, want %s", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestRoleSpecsMatchSpecRole(t *testing.T) {
+	for _, role := range []string{RoleTank, RoleHealer} {
+		specs := RoleSpecs(role)
+		if len(specs) == 0 {
+			t.Errorf("RoleSpecs(%s) is empty", role)
+		}
+		for _, spec := range specs {
+			if got := SpecRole(spec); got != role {
+				t.Errorf("RoleSpecs(%s) lists %q, which SpecRole maps to %s", role, spec, got)
+			}
+		}
+	}
+
+	// The leaderboard finds damage dealers by excluding these specs.
+	for _, spec := range NonDamageSpecs() {
+		if SpecRole(spec) == RoleDamage {
+			t.Errorf("NonDamageSpecs lists damage spec %q", spec)
+		}
+	}
+}
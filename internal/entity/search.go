@@ -0,0 +1,25 @@
+package entity
+
+type SearchFilter struct {
+	Query string
+	Realm string
+	Class string
+	Guild string
+	Limit int
+}
+
+type CharacterMatch struct {
+	Character
+	Similarity float64 `json:"similarity"`
+}
+
+type GuildMatch struct {
+	Guild
+	Similarity float64 `json:"similarity"`
+}
+
+type SearchResult struct {
+	Query      string           `json:"query"`
+	Characters []CharacterMatch `json:"characters"`
+	Guilds     []GuildMatch     `json:"guilds"`
+}
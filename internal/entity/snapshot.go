@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// CharacterSnapshot records a character's progression as of one sync.
+type CharacterSnapshot struct {
+	CharacterID int       `json:"character_id" db:"character_id"`
+	BlizzardID  string    `json:"blizzard_id" db:"blizzard_id"`
+	Lvl         int       `json:"lvl" db:"lvl"`
+	Ilvl        int       `json:"ilvl" db:"ilvl"`
+	MythicScore float64   `json:"mythic_score" db:"mythic_score"`
+	RecordedAt  time.Time `json:"recorded_at" db:"recorded_at"`
+}
+
+// ChangedSnapshots returns a snapshot for every fetched character that is new
+// or whose level, item level or M+ score differs from the stored one.
+func ChangedSnapshots(stored, fetched []Character) []CharacterSnapshot {
+	before := make(map[int]Character, len(stored))
+	for _, c := range stored {
+		before[c.CharacterID] = c
+	}
+
+	snapshots := make([]CharacterSnapshot, 0, len(fetched))
+	for _, c := range fetched {
+		old, ok := before[c.CharacterID]
+		if ok && old.Lvl == c.Lvl && old.Ilvl == c.Ilvl && old.MythicScore == c.MythicScore {
+			continue
+		}
+		snapshots = append(snapshots, CharacterSnapshot{
+			CharacterID: c.CharacterID,
+			BlizzardID:  c.BlizzardID,
+			Lvl:         c.Lvl,
+			Ilvl:        c.Ilvl,
+			MythicScore: c.MythicScore,
+		})
+	}
+	return snapshots
+}
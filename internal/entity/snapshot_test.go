@@ -0,0 +1,26 @@
+package entity
+
+import "testing"
+
+func TestChangedSnapshots(t *testing.T) {
+	stored := []Character{
+		{CharacterID: 1, Lvl: 80, Ilvl: 620, MythicScore: 2400},
+		{CharacterID: 2, Lvl: 80, Ilvl: 610, MythicScore: 1800},
+	}
+	fetched := []Character{
+		{CharacterID: 1, Lvl: 80, Ilvl: 620, MythicScore: 2400},
+		{CharacterID: 2, Lvl: 80, Ilvl: 615, MythicScore: 1800},
+		{CharacterID: 3, Lvl: 70, Ilvl: 500},
+	}
+
+	got := ChangedSnapshots(stored, fetched)
+	if len(got) != 2 {
+		t.Fatalf("got %d snapshots, want 2: %+v", len(got), got)
+	}
+	if got[0].CharacterID != 2 || got[0].Ilvl != 615 {
+		t.Errorf("first snapshot = %+v, want character 2 at ilvl 615", got[0])
+	}
+	if got[1].CharacterID != 3 {
+		t.Errorf("second snapshot = %+v, want new character 3", got[1])
+	}
+}
@@ -0,0 +1,14 @@
+package entity
+
+type AccountSummary struct {
+	BlizzardID         string                 `json:"blizzard_id"`
+	Battletag          string                 `json:"battletag"`
+	Main               *Character             `json:"main"`
+	Alts               []Character            `json:"-"`
+	AltsByClass        map[string][]Character `json:"alts_by_class"`
+	AltsByRole         map[string][]Character `json:"alts_by_role"`
+	MaxLevelAlts       int                    `json:"max_level_alts"`
+	HighestMythicScore float64                `json:"highest_mythic_score"`
+	AverageIlvl        float64                `json:"average_ilvl"`
+	Guilds             []Guild                `json:"guilds"`
+}
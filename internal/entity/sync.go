@@ -0,0 +1,35 @@
+package entity
+
+type HTTPValidator struct {
+	URL          string `json:"url" db:"url"`
+	ETag         string `json:"etag" db:"etag"`
+	LastModified string `json:"last_modified" db:"last_modified"`
+}
+
+// SyncBatch is the result of one Blizzard fetch. Characters whose documents
+// answered 304 Not Modified are only counted in Unchanged, and characters that
+// could not be fetched only in Failed; neither must be written back.
+type SyncBatch struct {
+	Characters []Character
+	Guilds     []Guild
+	Validators []HTTPValidator
+	Unchanged  int
+	Failed     int
+}
+
+// Guildless returns the IDs of fetched characters that are in no guild, so
+// their stored guild rows can be removed.
+func (b *SyncBatch) Guildless() []int {
+	inGuild := make(map[int]bool, len(b.Guilds))
+	for _, g := range b.Guilds {
+		inGuild[g.CharacterID] = true
+	}
+
+	ids := make([]int, 0)
+	for _, c := range b.Characters {
+		if !inGuild[c.CharacterID] {
+			ids = append(ids, c.CharacterID)
+		}
+	}
+	return ids
+}
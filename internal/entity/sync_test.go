@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSyncBatchGuildless(t *testing.T) {
+	batch := SyncBatch{
+		Characters: []Character{{CharacterID: 1}, {CharacterID: 2}, {CharacterID: 3}},
+		Guilds:     []Guild{{CharacterID: 2, GuildID: 10}},
+	}
+
+	if got, want := batch.Guildless(), []int{1, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Guildless() = %v, want %v", got, want)
+	}
+}
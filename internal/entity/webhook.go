@@ -0,0 +1,192 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	WebhookCharacterLevelUp      = "character.level_up"
+	WebhookMythicScoreThreshold  = "character.mythic_score_threshold"
+	WebhookMainChanged           = "profile.main_changed"
+	WebhookGuildMemberJoined     = "guild.member_joined"
+	WebhookGuildMemberLeft       = "guild.member_left"
+	WebhookGuildMemberRankChange = "guild.member_rank_changed"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+var WebhookEventTypes = []string{
+	WebhookCharacterLevelUp,
+	WebhookMythicScoreThreshold,
+	WebhookMainChanged,
+	WebhookGuildMemberJoined,
+	WebhookGuildMemberLeft,
+	WebhookGuildMemberRankChange,
+}
+
+type WebhookSubscription struct {
+	ID         int64     `json:"id" db:"id"`
+	BlizzardID string    `json:"-" db:"blizzard_id"`
+	URL        string    `json:"url" db:"url"`
+	Secret     string    `json:"secret,omitempty" db:"secret"`
+	EventTypes []string  `json:"event_types" db:"event_types"`
+	GuildID    *int      `json:"guild_id,omitempty" db:"guild_id"`
+	Active     bool      `json:"active" db:"active"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// OutboxEvent is written in the transaction that caused it. Account events
+// carry BlizzardID, guild events GuildID; subscriptions match on either.
+type OutboxEvent struct {
+	ID         int64           `json:"id" db:"id"`
+	Type       string          `json:"type" db:"event_type"`
+	BlizzardID string          `json:"-" db:"blizzard_id"`
+	GuildID    int             `json:"-" db:"guild_id"`
+	Payload    json.RawMessage `json:"data" db:"payload"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64           `json:"id" db:"id"`
+	SubscriptionID int64           `json:"subscription_id" db:"subscription_id"`
+	OutboxID       int64           `json:"outbox_id" db:"outbox_id"`
+	EventType      string          `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	ResponseCode   *int            `json:"response_code,omitempty" db:"response_code"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+	URL            string          `json:"-" db:"url"`
+	Secret         string          `json:"-" db:"secret"`
+}
+
+type CharacterLevelUp struct {
+	CharacterID int    `json:"character_id"`
+	Name        string `json:"name"`
+	Realm       string `json:"realm"`
+	OldLevel    int    `json:"old_level"`
+	NewLevel    int    `json:"new_level"`
+}
+
+type MythicScoreThreshold struct {
+	CharacterID int     `json:"character_id"`
+	Name        string  `json:"name"`
+	Realm       string  `json:"realm"`
+	Threshold   float64 `json:"threshold"`
+	OldScore    float64 `json:"old_score"`
+	NewScore    float64 `json:"new_score"`
+}
+
+type MainChanged struct {
+	OldCharacterID int    `json:"old_character_id,omitempty"`
+	OldName        string `json:"old_name,omitempty"`
+	CharacterID    int    `json:"character_id"`
+	Name           string `json:"name"`
+	Realm          string `json:"realm"`
+}
+
+func IsWebhookEventType(t string) bool {
+	for _, known := range WebhookEventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// CharacterWebhookEvents compares stored characters with freshly fetched ones
+// and returns level-ups and the highest M+ score threshold each one crossed.
+// Characters seen for the first time produce no events.
+func CharacterWebhookEvents(blizzardID string, stored, fetched []Character, thresholds []float64) ([]OutboxEvent, error) {
+	before := make(map[int]Character, len(stored))
+	for _, c := range stored {
+		before[c.CharacterID] = c
+	}
+
+	events := make([]OutboxEvent, 0)
+	for _, c := range fetched {
+		old, ok := before[c.CharacterID]
+		if !ok {
+			continue
+		}
+
+		if c.Lvl > old.Lvl {
+			event, err := NewOutboxEvent(WebhookCharacterLevelUp, blizzardID, 0, CharacterLevelUp{
+				CharacterID: c.CharacterID,
+				Name:        c.Name,
+				Realm:       c.Realm,
+				OldLevel:    old.Lvl,
+				NewLevel:    c.Lvl,
+			})
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, event)
+		}
+
+		crossed, ok := highestCrossed(thresholds, old.MythicScore, c.MythicScore)
+		if !ok {
+			continue
+		}
+		event, err := NewOutboxEvent(WebhookMythicScoreThreshold, blizzardID, 0, MythicScoreThreshold{
+			CharacterID: c.CharacterID,
+			Name:        c.Name,
+			Realm:       c.Realm,
+			Threshold:   crossed,
+			OldScore:    old.MythicScore,
+			NewScore:    c.MythicScore,
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func highestCrossed(thresholds []float64, old, current float64) (float64, bool) {
+	var crossed float64
+	found := false
+	for _, t := range thresholds {
+		if old < t && current >= t && (!found || t > crossed) {
+			crossed, found = t, true
+		}
+	}
+	return crossed, found
+}
+
+// GuildWebhookEvents maps roster diff events onto webhook events.
+func GuildWebhookEvents(events []GuildEvent) ([]OutboxEvent, error) {
+	types := map[string]string{
+		GuildEventJoin:       WebhookGuildMemberJoined,
+		GuildEventLeave:      WebhookGuildMemberLeft,
+		GuildEventRankChange: WebhookGuildMemberRankChange,
+	}
+
+	out := make([]OutboxEvent, 0, len(events))
+	for _, e := range events {
+		event, err := NewOutboxEvent(types[e.Type], "", e.GuildID, e)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, event)
+	}
+	return out, nil
+}
+
+func NewOutboxEvent(eventType, blizzardID string, guildID int, payload any) (OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+	return OutboxEvent{Type: eventType, BlizzardID: blizzardID, GuildID: guildID, Payload: data}, nil
+}
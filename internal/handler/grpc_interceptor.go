@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"profile-service/internal/adapter/database"
+	logger "profile-service/pkg/log"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const metadataRequestID = "x-request-id"
+
+type serviceToken struct {
+	service string
+	token   []byte
+}
+
+// GRPCLogging puts a request-scoped logger into the context, as the HTTP
+// RequestID middleware does, logs every call and turns panics into Internal.
+func GRPCLogging(log *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (resp any, err error) {
+		id := firstMetadata(ctx, metadataRequestID)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, id))
+
+		entry := log.WithFields(logrus.Fields{
+			logger.RequestIDField: id,
+			"grpc_method":         info.FullMethod,
+		})
+		ctx = logger.WithEntry(ctx, entry)
+
+		start := time.Now()
+		defer func() {
+			if r := recover(); r != nil {
+				entry.WithField("panic", r).Error("gRPC handler panicked")
+				err = status.Error(codes.Internal, "internal error")
+			}
+			entry.WithFields(logrus.Fields{
+				"code":     status.Code(err).String(),
+				"duration": time.Since(start),
+			}).Info("gRPC call finished")
+		}()
+
+		return next(ctx, req)
+	}
+}
+
+// GRPCAuth accepts calls carrying "authorization: Bearer <token>" for one of
+// the configured "<service>:<token>" entries. The health service stays open
+// so orchestrators can probe it.
+func GRPCAuth(entries []string, log *logrus.Logger) grpc.UnaryServerInterceptor {
+	tokens := make([]serviceToken, 0, len(entries))
+	for _, entry := range entries {
+		service, token, _ := strings.Cut(entry, ":")
+		tokens = append(tokens, serviceToken{service: service, token: []byte(token)})
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		if strings.HasPrefix(info.FullMethod, "/"+grpc_health_v1.Health_ServiceDesc.ServiceName+"/") {
+			return next(ctx, req)
+		}
+
+		presented := strings.TrimPrefix(firstMetadata(ctx, "authorization"), "Bearer ")
+		if presented == "" {
+			logger.FromContext(ctx, log).Warn("gRPC call without service token")
+			return nil, status.Error(codes.Unauthenticated, "missing service token")
+		}
+
+		service := ""
+		for _, t := range tokens {
+			if subtle.ConstantTimeCompare([]byte(presented), t.token) == 1 {
+				service = t.service
+			}
+		}
+		if service == "" {
+			logger.FromContext(ctx, log).Warn("gRPC call with unknown service token")
+			return nil, status.Error(codes.Unauthenticated, "invalid service token")
+		}
+
+		entry := logger.FromContext(ctx, log).WithField("caller", service)
+		return next(logger.WithEntry(ctx, entry), req)
+	}
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// grpcError maps usecase errors onto status codes, using the same message
+// checks as the REST handlers.
+func grpcError(err error) error {
+	msg := err.Error()
+	switch {
+	case errors.Is(err, database.ErrCharacterAmbiguous):
+		return status.Error(codes.FailedPrecondition, msg)
+	case strings.Contains(msg, "not found"):
+		return status.Error(codes.NotFound, msg)
+	case strings.Contains(msg, "is empty"):
+		return status.Error(codes.InvalidArgument, msg)
+	case upstreamStatus(err, 0) != 0:
+		return status.Error(codes.Unavailable, msg)
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	profilev1 "profile-service/api/profile/v1"
+	"profile-service/internal/entity"
+	logger "profile-service/pkg/log"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		wantCode   codes.Code
+		wantCaller string
+	}{
+		{name: "missing token", ctx: context.Background(), wantCode: codes.Unauthenticated},
+		{name: "empty bearer", ctx: withToken(""), wantCode: codes.Unauthenticated},
+		{name: "wrong token", ctx: withToken("not-a-configured-token"), wantCode: codes.Unauthenticated},
+		{name: "whole config entry", ctx: withToken("worker:" + workerToken), wantCode: codes.Unauthenticated},
+		{name: "worker token", ctx: withToken(workerToken), wantCode: codes.OK, wantCaller: "worker"},
+		{name: "billing token", ctx: withToken(billingToken), wantCode: codes.OK, wantCaller: "billing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &fakeProfileUsecase{main: &entity.Character{CharacterID: 1}}
+			client, _ := newGRPCClient(t, uc)
+
+			_, err := client.GetMainCharacter(tt.ctx, &profilev1.GetMainCharacterRequest{BlizzardId: "100"})
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("code = %s, want %s (%v)", code, tt.wantCode, err)
+			}
+			if tt.wantCode != codes.OK {
+				if uc.called {
+					t.Error("rejected call reached the usecase")
+				}
+				return
+			}
+
+			// The token decides which service the call is attributed to.
+			if got := logger.FromContext(uc.ctx, nil).Data["caller"]; got != tt.wantCaller {
+				t.Errorf("caller = %v, want %s", got, tt.wantCaller)
+			}
+		})
+	}
+}
+
+func TestGRPCAuthLeavesHealthOpen(t *testing.T) {
+	_, conn := newGRPCClient(t, &fakeProfileUsecase{})
+
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{
+		Service: profilev1.ProfileService_ServiceDesc.ServiceName,
+	})
+	if err != nil {
+		t.Fatalf("health check without token: %v", err)
+	}
+	if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("status = %s, want SERVING", resp.GetStatus())
+	}
+}
+
+func TestGRPCLoggingRequestID(t *testing.T) {
+	uc := &fakeProfileUsecase{main: &entity.Character{CharacterID: 1}}
+	client, _ := newGRPCClient(t, uc)
+
+	tests := []struct {
+		name string
+		sent string
+		want string
+	}{
+		{name: "kept when valid", sent: "req-0123456789abcdef", want: "req-0123456789abcdef"},
+		{name: "replaced when invalid", sent: "bad id/with spaces"},
+		{name: "generated when missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := withToken(workerToken)
+			if tt.sent != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, metadataRequestID, tt.sent)
+			}
+
+			var header metadata.MD
+			if _, err := client.GetMainCharacter(ctx, &profilev1.GetMainCharacterRequest{BlizzardId: "100"}, grpc.Header(&header)); err != nil {
+				t.Fatalf("GetMainCharacter: %v", err)
+			}
+
+			got := header.Get(metadataRequestID)
+			if len(got) != 1 || got[0] == "" {
+				t.Fatalf("response request id = %v", got)
+			}
+			if tt.want != "" && got[0] != tt.want {
+				t.Errorf("request id = %q, want %q", got[0], tt.want)
+			}
+			if tt.want == "" && got[0] == tt.sent {
+				t.Errorf("request id %q was not replaced", got[0])
+			}
+			if logged := logger.RequestID(uc.ctx); logged != got[0] {
+				t.Errorf("logged request id = %q, want %q", logged, got[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"profile-service/internal/entity"
+	"profile-service/internal/usecase"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type HealthHandler struct {
+	uc    usecase.HealthUsecase
+	ready atomic.Bool
+	log   *logrus.Logger
+}
+
+func NewHealthHandler(uc usecase.HealthUsecase, log *logrus.Logger) *HealthHandler {
+	return &HealthHandler{
+		uc:  uc,
+		log: log,
+	}
+}
+
+// SetReady toggles readiness; main flips it off before shutting the server
+// down so load balancers stop routing new requests first.
+func (h *HealthHandler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": entity.HealthUp})
+}
+
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	if !h.ready.Load() {
+		c.JSON(http.StatusServiceUnavailable, entity.Readiness{
+			Status:       entity.HealthDown,
+			Dependencies: []entity.DependencyStatus{},
+		})
+		return
+	}
+
+	readiness := h.uc.Readiness(c.Request.Context())
+	if readiness.Status == entity.HealthDown {
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+
+	c.JSON(http.StatusOK, readiness)
+}
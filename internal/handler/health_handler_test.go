@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"profile-service/internal/entity"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type fakeHealthUsecase struct {
+	readiness entity.Readiness
+	calls     int
+}
+
+func (f *fakeHealthUsecase) Readiness(context.Context) *entity.Readiness {
+	f.calls++
+	r := f.readiness
+	return &r
+}
+
+func TestReadinessFollowsSetReady(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	uc := &fakeHealthUsecase{readiness: entity.Readiness{Status: entity.HealthUp, Dependencies: []entity.DependencyStatus{}}}
+	h := NewHealthHandler(uc, log)
+	router := gin.New()
+	router.GET("/readyz", h.Readiness)
+
+	probe := func() (int, string) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var body entity.Readiness
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode readiness: %v", err)
+		}
+		return rec.Code, body.Status
+	}
+
+	steps := []struct {
+		name       string
+		ready      *bool
+		wantCode   int
+		wantStatus string
+		wantCalls  int
+	}{
+		{name: "before startup", wantCode: http.StatusServiceUnavailable, wantStatus: entity.HealthDown},
+		{name: "started", ready: boolPtr(true), wantCode: http.StatusOK, wantStatus: entity.HealthUp, wantCalls: 1},
+		{name: "shutting down", ready: boolPtr(false), wantCode: http.StatusServiceUnavailable, wantStatus: entity.HealthDown, wantCalls: 1},
+	}
+
+	for _, s := range steps {
+		if s.ready != nil {
+			h.SetReady(*s.ready)
+		}
+		code, status := probe()
+		if code != s.wantCode || status != s.wantStatus {
+			t.Errorf("%s: readiness = %d %s, want %d %s", s.name, code, status, s.wantCode, s.wantStatus)
+		}
+		// Once draining, dependencies are no longer checked.
+		if uc.calls != s.wantCalls {
+			t.Errorf("%s: dependencies checked %d times, want %d", s.name, uc.calls, s.wantCalls)
+		}
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
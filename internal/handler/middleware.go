@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	logger "profile-service/pkg/log"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const HeaderRequestID = "X-Request-ID"
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9\-_.:]{1,128}$`)
+
+// RequestID accepts a well-formed incoming X-Request-ID or generates one, echoes
+// it back and puts a logger entry carrying it into the request context.
+func RequestID(log *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(HeaderRequestID)
+		if !requestIDPattern.MatchString(id) {
+			id = newRequestID()
+		}
+		c.Header(HeaderRequestID, id)
+
+		ctx := c.Request.Context()
+		fields := logrus.Fields{
+			logger.RequestIDField: id,
+			"method":              c.Request.Method,
+			"path":                c.Request.URL.Path,
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			fields["trace_id"] = sc.TraceID().String()
+		}
+
+		c.Request = c.Request.WithContext(logger.WithEntry(ctx, log.WithFields(fields)))
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
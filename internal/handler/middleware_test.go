@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	logger "profile-service/pkg/log"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	tests := []struct {
+		name string
+		sent string
+		want string
+	}{
+		{name: "kept when valid", sent: "req-0123456789abcdef", want: "req-0123456789abcdef"},
+		{name: "replaced when invalid", sent: "bad id/with spaces"},
+		{name: "replaced when too long", sent: strings.Repeat("a", 129)},
+		{name: "generated when missing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ctx context.Context
+			router := gin.New()
+			router.Use(RequestID(log))
+			router.GET("/profile/characters", func(c *gin.Context) { ctx = c.Request.Context() })
+
+			req := httptest.NewRequest(http.MethodGet, "/profile/characters", nil)
+			if tt.sent != "" {
+				req.Header.Set(HeaderRequestID, tt.sent)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(HeaderRequestID)
+			if !requestIDPattern.MatchString(got) {
+				t.Fatalf("response request id = %q", got)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("request id = %q, want %q", got, tt.want)
+			}
+			if tt.want == "" && got == tt.sent {
+				t.Errorf("request id %q was not replaced", got)
+			}
+
+			// Handlers, usecases and adapters log with the id the client saw.
+			if logged := logger.RequestID(ctx); logged != got {
+				t.Errorf("context request id = %q, want %q", logged, got)
+			}
+			entry := logger.FromContext(ctx, log)
+			if entry.Data["path"] != "/profile/characters" || entry.Data["method"] != http.MethodGet {
+				t.Errorf("entry fields = %v", entry.Data)
+			}
+			if _, ok := entry.Data["trace_id"]; ok {
+				t.Errorf("trace_id logged without a span")
+			}
+		})
+	}
+}
+
+func TestRequestIDLogsTraceID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	})
+
+	var entry *logrus.Entry
+	router := gin.New()
+	// Stands in for otelgin, which runs first and puts the span in the context.
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(trace.ContextWithSpanContext(c.Request.Context(), sc))
+	})
+	router.Use(RequestID(log))
+	router.GET("/profile/characters", func(c *gin.Context) { entry = logger.FromContext(c.Request.Context(), log) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/profile/characters", nil))
+
+	if got := entry.Data["trace_id"]; got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace_id = %v", got)
+	}
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"context"
+	profilev1 "profile-service/api/profile/v1"
+	"profile-service/internal/adapter/blizzard"
+	"profile-service/internal/entity"
+	"profile-service/internal/usecase"
+	logger "profile-service/pkg/log"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type ProfileGRPCHandler struct {
+	profilev1.UnimplementedProfileServiceServer
+	blizzAd blizzard.BlizzardRepository
+	uc      usecase.ProfileUsecase
+	log     *logrus.Logger
+}
+
+func NewProfileGRPCHandler(
+	blizzAd blizzard.BlizzardRepository,
+	uc usecase.ProfileUsecase,
+	log *logrus.Logger,
+) *ProfileGRPCHandler {
+	return &ProfileGRPCHandler{
+		blizzAd: blizzAd,
+		uc:      uc,
+		log:     log,
+	}
+}
+
+func (h *ProfileGRPCHandler) GetCharacters(ctx context.Context, req *profilev1.GetCharactersRequest) (*profilev1.GetCharactersResponse, error) {
+	var (
+		profile *entity.Profile
+		err     error
+	)
+	if req.GetUserJwt() == "" {
+		profile, err = h.uc.GetStoredCharacters(ctx, req.GetBlizzardId())
+	} else {
+		accessToken, tokenErr := h.userAccessToken(ctx, req.GetBlizzardId(), req.GetAccessToken(), req.GetUserJwt())
+		if tokenErr != nil {
+			return nil, tokenErr
+		}
+		profile, err = h.uc.GetCharacters(ctx, req.GetBlizzardId(), accessToken, req.GetUserJwt())
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	resp := &profilev1.GetCharactersResponse{
+		Characters: make([]*profilev1.Character, 0, len(profile.Characters)),
+		Degraded:   profile.Degraded,
+	}
+	if !profile.SyncedAt.IsZero() {
+		resp.SyncedAt = timestamppb.New(profile.SyncedAt)
+	}
+	for _, char := range profile.Characters {
+		resp.Characters = append(resp.Characters, toProtoCharacter(char))
+	}
+
+	return resp, nil
+}
+
+func (h *ProfileGRPCHandler) GetMainCharacter(ctx context.Context, req *profilev1.GetMainCharacterRequest) (*profilev1.Character, error) {
+	if req.GetBlizzardId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "blizzard_id is required")
+	}
+
+	char, err := h.uc.GetMainCharacterByBlizzardID(ctx, req.GetBlizzardId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if char == nil {
+		return nil, status.Error(codes.NotFound, "character not found")
+	}
+
+	return toProtoCharacter(*char), nil
+}
+
+func (h *ProfileGRPCHandler) GetGuild(ctx context.Context, req *profilev1.GetGuildRequest) (*profilev1.Guild, error) {
+	guild, err := h.uc.GetGuildByName(ctx, req.GetName(), req.GetRealm())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	if guild == nil {
+		return nil, status.Error(codes.NotFound, "guild not found")
+	}
+
+	return &profilev1.Guild{
+		GuildId:   int32(guild.GuildID),
+		Name:      guild.Name,
+		NameSlug:  guild.NameSlug,
+		Realm:     guild.Realm,
+		RealmSlug: guild.RealmSlug,
+		Faction:   guild.Faction,
+	}, nil
+}
+
+func (h *ProfileGRPCHandler) RefreshCharacters(ctx context.Context, req *profilev1.RefreshCharactersRequest) (*profilev1.RefreshCharactersResponse, error) {
+	if req.GetUserJwt() == "" {
+		batch, err := h.uc.SyncAccount(ctx, req.GetBlizzardId())
+		if err != nil {
+			return nil, grpcError(err)
+		}
+		return &profilev1.RefreshCharactersResponse{
+			Updated:   int32(len(batch.Characters)),
+			Unchanged: int32(batch.Unchanged),
+		}, nil
+	}
+
+	accessToken, err := h.userAccessToken(ctx, req.GetBlizzardId(), req.GetAccessToken(), req.GetUserJwt())
+	if err != nil {
+		return nil, err
+	}
+	if err := h.uc.RefreshCharacters(ctx, req.GetBlizzardId(), accessToken, req.GetUserJwt()); err != nil {
+		return nil, grpcError(err)
+	}
+
+	return &profilev1.RefreshCharactersResponse{}, nil
+}
+
+func (h *ProfileGRPCHandler) SetMainCharacter(ctx context.Context, req *profilev1.SetMainCharacterRequest) (*profilev1.SetMainCharacterResponse, error) {
+	if err := h.uc.SetMain(ctx, req.GetBlizzardId(), req.GetRealm(), req.GetCharacterName()); err != nil {
+		return nil, grpcError(err)
+	}
+	return &profilev1.SetMainCharacterResponse{}, nil
+}
+
+// userAccessToken checks that userJWT belongs to blizzardID and returns the
+// user's Blizzard token, resolving it through auth_service when not sent.
+func (h *ProfileGRPCHandler) userAccessToken(ctx context.Context, blizzardID, accessToken, userJWT string) (string, error) {
+	user, err := h.blizzAd.GetUserData(ctx, userJWT)
+	if err != nil {
+		return "", h.authError(ctx, err)
+	}
+	if user.ID != blizzardID {
+		h.logFor(ctx).WithField("blizzard_id", blizzardID).Warn("user token belongs to another account")
+		return "", status.Error(codes.PermissionDenied, "user token does not match blizzard_id")
+	}
+
+	if accessToken != "" {
+		return accessToken, nil
+	}
+
+	token, err := h.blizzAd.GetBlizzardAccessToken(ctx, userJWT)
+	if err != nil {
+		return "", h.authError(ctx, err)
+	}
+	return token, nil
+}
+
+func (h *ProfileGRPCHandler) authError(ctx context.Context, err error) error {
+	h.logFor(ctx).WithError(err).Warn("failed resolve user token")
+	if upstreamStatus(err, 0) != 0 {
+		return status.Error(codes.Unavailable, "auth service unavailable")
+	}
+	return status.Error(codes.Unauthenticated, "invalid user token")
+}
+
+func toProtoCharacter(char entity.Character) *profilev1.Character {
+	return &profilev1.Character{
+		CharacterId: int32(char.CharacterID),
+		BlizzardId:  char.BlizzardID,
+		Battletag:   char.Battletag,
+		Name:        char.Name,
+		Realm:       char.Realm,
+		RealmSlug:   char.RealmSlug,
+		Race:        char.Race,
+		Faction:     char.Faction,
+		Class:       char.Class,
+		Spec:        char.Spec,
+		Level:       int32(char.Lvl),
+		ItemLevel:   int32(char.Ilvl),
+		Guild:       char.Guild,
+		MythicScore: char.MythicScore,
+		IsMain:      char.IsMain,
+		Hidden:      char.Hidden,
+		Note:        char.Note,
+	}
+}
+
+func (h *ProfileGRPCHandler) logFor(ctx context.Context) *logrus.Entry {
+	return logger.FromContext(ctx, h.log)
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	profilev1 "profile-service/api/profile/v1"
+	"profile-service/internal/adapter/database"
+	"profile-service/internal/entity"
+	"profile-service/internal/usecase"
+	"profile-service/pkg/errors"
+	"profile-service/pkg/resilience"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	workerToken  = "worker-token-0123456789"
+	billingToken = "billing-token-0123456789"
+)
+
+// fakeProfileUsecase answers the calls the gRPC tests make and records what
+// reached it.
+type fakeProfileUsecase struct {
+	usecase.ProfileUsecase
+	err    error
+	main   *entity.Character
+	ctx    context.Context
+	realm  string
+	called bool
+}
+
+func (f *fakeProfileUsecase) GetMainCharacterByBlizzardID(ctx context.Context, _ string) (*entity.Character, error) {
+	f.ctx, f.called = ctx, true
+	return f.main, f.err
+}
+
+func (f *fakeProfileUsecase) SetMain(ctx context.Context, _, realm, _ string) error {
+	f.ctx, f.realm, f.called = ctx, realm, true
+	return f.err
+}
+
+// newGRPCClient serves uc over an in-memory listener with the same
+// interceptors and health service as the serve command.
+func newGRPCClient(t *testing.T, uc usecase.ProfileUsecase) (profilev1.ProfileServiceClient, *grpc.ClientConn) {
+	t.Helper()
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		GRPCLogging(log),
+		GRPCAuth([]string{"worker:" + workerToken, "billing:" + billingToken}, log),
+	))
+	profilev1.RegisterProfileServiceServer(srv, NewProfileGRPCHandler(nil, uc, log))
+
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus(profilev1.ProfileService_ServiceDesc.ServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(srv, healthSrv)
+
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial bufconn: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return profilev1.NewProfileServiceClient(conn), conn
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func TestGRPCErrorCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		want    codes.Code
+		wantMsg string
+	}{
+		{name: "not found", err: database.ErrCharacterNotFound, want: codes.NotFound},
+		{name: "ambiguous", err: database.ErrCharacterAmbiguous, want: codes.FailedPrecondition},
+		{name: "empty argument", err: errors.NewAppError("blizzardID or charcater name is empty", nil), want: codes.InvalidArgument},
+		{name: "open circuit", err: fmt.Errorf("fetch: %w", resilience.ErrCircuitOpen), want: codes.Unavailable},
+		{
+			name:    "internal error hides details",
+			err:     errors.NewAppError("failed execute SQL", stderrors.New("password authentication failed")),
+			want:    codes.Internal,
+			wantMsg: "internal error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newGRPCClient(t, &fakeProfileUsecase{err: tt.err})
+
+			_, err := client.SetMainCharacter(withToken(workerToken), &profilev1.SetMainCharacterRequest{
+				BlizzardId:    "100",
+				CharacterName: "Jaina",
+			})
+			st := status.Convert(err)
+			if st.Code() != tt.want {
+				t.Errorf("code = %s, want %s (%v)", st.Code(), tt.want, err)
+			}
+			if tt.wantMsg != "" && st.Message() != tt.wantMsg {
+				t.Errorf("message = %q, want %q", st.Message(), tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestGRPCGetMainCharacter(t *testing.T) {
+	uc := &fakeProfileUsecase{main: &entity.Character{CharacterID: 7, BlizzardID: "100", Name: "Jaina", RealmSlug: "silvermoon", Lvl: 80}}
+	client, _ := newGRPCClient(t, uc)
+
+	if _, err := client.GetMainCharacter(withToken(workerToken), &profilev1.GetMainCharacterRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("empty blizzard_id: code = %s, want InvalidArgument", status.Code(err))
+	}
+	if uc.called {
+		t.Error("usecase called without blizzard_id")
+	}
+
+	char, err := client.GetMainCharacter(withToken(workerToken), &profilev1.GetMainCharacterRequest{BlizzardId: "100"})
+	if err != nil {
+		t.Fatalf("GetMainCharacter: %v", err)
+	}
+	if char.GetCharacterId() != 7 || char.GetName() != "Jaina" || char.GetLevel() != 80 || char.GetRealmSlug() != "silvermoon" {
+		t.Errorf("character = %+v", char)
+	}
+}
+
+func TestGRPCSetMainCharacterPassesRealm(t *testing.T) {
+	uc := &fakeProfileUsecase{}
+	client, _ := newGRPCClient(t, uc)
+
+	if _, err := client.SetMainCharacter(withToken(workerToken), &profilev1.SetMainCharacterRequest{
+		BlizzardId:    "100",
+		CharacterName: "Jaina",
+		Realm:         "Argent Dawn",
+	}); err != nil {
+		t.Fatalf("SetMainCharacter: %v", err)
+	}
+	if uc.realm != "Argent Dawn" {
+		t.Errorf("realm = %q, want Argent Dawn", uc.realm)
+	}
+}
@@ -1,12 +1,19 @@
 package handler
 
 import (
+	"errors"
 	"math"
 	"net/http"
 	"profile-service/internal/adapter/blizzard"
+	"profile-service/internal/adapter/database"
+	"profile-service/internal/entity"
 	"profile-service/internal/usecase"
 	"profile-service/pkg/dto"
+	logger "profile-service/pkg/log"
+	"profile-service/pkg/resilience"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -33,49 +40,52 @@ func NewProfileHandler(
 func (h *ProfileHandler) GetCharacters(c *gin.Context) {
 	tokenStr := c.GetHeader("Authorization")
 	if tokenStr == "" {
-		h.log.Error("Auth header is missing")
+		h.logFor(c).Error("Auth header is missing")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing auth header"})
 		return
 	}
 	token := strings.TrimPrefix(tokenStr, "Bearer ")
 	if token == "" {
-		h.log.Error("Invalid Bearer token")
+		h.logFor(c).Error("Invalid Bearer token")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Bearer token"})
 		return
 	}
 
 	tokenAccess, err := h.blizzAd.GetBlizzardAccessToken(c.Request.Context(), token)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid access token"})
+		c.JSON(upstreamStatus(err, http.StatusBadRequest), gin.H{"error": "invalid access token"})
 		return
 	}
 
 	user, err := h.blizzAd.GetUserData(c.Request.Context(), token)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid access token"})
+		c.JSON(upstreamStatus(err, http.StatusBadRequest), gin.H{"error": "invalid access token"})
 		return
 	}
 
-	characters, err := h.uc.GetCharacters(c.Request.Context(), user.ID, tokenAccess, token)
+	profile, err := h.uc.GetCharacters(c.Request.Context(), user.ID, tokenAccess, token)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "failed parse characters"})
+		c.JSON(upstreamStatus(err, http.StatusBadRequest), gin.H{"error": "failed parse characters"})
 		return
 	}
 
-	characterResponses := make([]dto.CharacterResponse, len(characters))
-	for i, char := range characters {
+	characterResponses := make([]dto.CharacterResponse, len(profile.Characters))
+	for i, char := range profile.Characters {
 		characterResponses[i] = dto.CharacterResponse{
-			Name:        char.Name,
-			Realm:       char.Realm,
-			Race:        char.Race,
-			Faction:     char.Faction,
-			Class:       char.Class,
-			Spec:        char.Spec,
-			Lvl:         char.Lvl,
-			Ilvl:        char.Ilvl,
-			Guild:       char.Guild,
-			MythicScore: math.Round(char.MythicScore*100) / 100,
-			IsMain:      char.IsMain,
+			Name:         char.Name,
+			Realm:        char.Realm,
+			Race:         char.Race,
+			Faction:      char.Faction,
+			Class:        char.Class,
+			Spec:         char.Spec,
+			Lvl:          char.Lvl,
+			Ilvl:         char.Ilvl,
+			Guild:        char.Guild,
+			MythicScore:  math.Round(char.MythicScore*100) / 100,
+			IsMain:       char.IsMain,
+			Hidden:       char.Hidden,
+			DisplayOrder: char.DisplayOrder,
+			Note:         char.Note,
 		}
 	}
 
@@ -83,6 +93,8 @@ func (h *ProfileHandler) GetCharacters(c *gin.Context) {
 		BlizzardID: user.ID,
 		Battletag:  user.Battletag,
 		Characters: characterResponses,
+		SyncedAt:   profile.SyncedAt,
+		Degraded:   profile.Degraded,
 	}
 
 	c.JSON(http.StatusOK, profileResponse)
@@ -91,32 +103,40 @@ func (h *ProfileHandler) GetCharacters(c *gin.Context) {
 func (h ProfileHandler) SetMainCharacter(c *gin.Context) {
 	charName := c.Query("character")
 	if charName == "" {
-		h.log.Error("Character name is missing")
+		h.logFor(c).Error("Character name is missing")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing char name"})
 		return
 	}
 
 	jwtToken := c.GetHeader("Authorization")
 	if jwtToken == "" {
-		h.log.Error("Auth header is missing")
+		h.logFor(c).Error("Auth header is missing")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing auth header"})
 		return
 	}
 
 	token := strings.TrimPrefix(jwtToken, "Bearer ")
 	if token == "" {
-		h.log.Error("Invalid Bearer token")
+		h.logFor(c).Error("Invalid Bearer token")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Bearer token"})
 		return
 	}
 
 	user, err := h.blizzAd.GetUserData(c.Request.Context(), token)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid access token"})
+		c.JSON(upstreamStatus(err, http.StatusBadRequest), gin.H{"error": "invalid access token"})
 		return
 	}
 
-	if err := h.uc.SetMain(c.Request.Context(), user.ID, charName); err != nil {
+	if err := h.uc.SetMain(c.Request.Context(), user.ID, c.Query("realm"), charName); err != nil {
+		if errors.Is(err, database.ErrCharacterNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "character not found"})
+			return
+		}
+		if errors.Is(err, database.ErrCharacterAmbiguous) {
+			c.JSON(http.StatusConflict, gin.H{"error": "character name matches several realms, pass realm"})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": "failed set main character"})
 		return
 	}
@@ -127,56 +147,116 @@ func (h ProfileHandler) SetMainCharacter(c *gin.Context) {
 	})
 }
 
+func (h *ProfileHandler) UpdatePreference(c *gin.Context) {
+	charName := c.Query("character")
+	if charName == "" {
+		h.logFor(c).Error("Character name is missing")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing char name"})
+		return
+	}
+
+	jwtToken := c.GetHeader("Authorization")
+	if jwtToken == "" {
+		h.logFor(c).Error("Auth header is missing")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing auth header"})
+		return
+	}
+
+	token := strings.TrimPrefix(jwtToken, "Bearer ")
+	if token == "" {
+		h.logFor(c).Error("Invalid Bearer token")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Bearer token"})
+		return
+	}
+
+	var req dto.PreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logFor(c).WithError(err).Error("Invalid preference body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	user, err := h.blizzAd.GetUserData(c.Request.Context(), token)
+	if err != nil {
+		c.JSON(upstreamStatus(err, http.StatusBadRequest), gin.H{"error": "invalid access token"})
+		return
+	}
+
+	pref := entity.Preference{
+		Hidden:       req.Hidden,
+		DisplayOrder: req.DisplayOrder,
+		Note:         req.Note,
+	}
+	if err := h.uc.UpdatePreference(c.Request.Context(), user.ID, c.Query("realm"), charName, pref); err != nil {
+		if errors.Is(err, database.ErrCharacterNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "character not found"})
+			return
+		}
+		if errors.Is(err, database.ErrCharacterAmbiguous) {
+			c.JSON(http.StatusConflict, gin.H{"error": "character name matches several realms, pass realm"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed update character preference"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Character preference updated",
+		"character":  charName,
+		"preference": pref,
+	})
+}
+
 func (h *ProfileHandler) RefreshCharacters(c *gin.Context) {
 	tokenStr := c.GetHeader("Authorization")
 	if tokenStr == "" {
-		h.log.Error("Auth header is missing")
+		h.logFor(c).Error("Auth header is missing")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing auth header"})
 		return
 	}
 	token := strings.TrimPrefix(tokenStr, "Bearer ")
 	if token == "" {
-		h.log.Error("Invalid Bearer token")
+		h.logFor(c).Error("Invalid Bearer token")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Bearer token"})
 		return
 	}
 
 	user, err := h.blizzAd.GetUserData(c.Request.Context(), token)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid access token"})
+		c.JSON(upstreamStatus(err, http.StatusBadRequest), gin.H{"error": "invalid access token"})
 		return
 	}
 
 	tokenAccess, err := h.blizzAd.GetBlizzardAccessToken(c.Request.Context(), token)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid access token"})
+		c.JSON(upstreamStatus(err, http.StatusBadRequest), gin.H{"error": "invalid access token"})
 		return
 	}
 
 	if err := h.uc.RefreshCharacters(c.Request.Context(), user.ID, tokenAccess, token); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "failed refresh characters"})
+		c.JSON(upstreamStatus(err, http.StatusBadRequest), gin.H{"error": "failed refresh characters"})
 		return
 	}
 
-	characters, err := h.uc.GetCharacters(c.Request.Context(), user.ID, tokenAccess, token)
+	profile, err := h.uc.GetCharacters(c.Request.Context(), user.ID, tokenAccess, token)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "failed parse characters"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"characters": characters})
+	c.JSON(http.StatusOK, gin.H{"characters": profile.Characters})
 }
 
 func (h *ProfileHandler) GetGuild(c *gin.Context) {
 	guildName := c.Query("name")
 
 	if guildName == "" {
-		h.log.Error("Guild name is missing")
+		h.logFor(c).Error("Guild name is missing")
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing guild name"})
 		return
 	}
 
-	guild, err := h.uc.GetGuildByName(c.Request.Context(), guildName)
+	guild, err := h.uc.GetGuildByName(c.Request.Context(), guildName, c.Query("realm"))
 	if err != nil {
 		if strings.Contains(err.Error(), "guild not found") {
 			c.JSON(http.StatusNotFound, gin.H{"error": "guild not found"})
@@ -202,7 +282,7 @@ func (h *ProfileHandler) GetMainCharacter(c *gin.Context) {
 	blizzardID := c.Query("blizzard_id")
 
 	if blizzardID == "" {
-		h.log.Error("Blizzard ID is missing")
+		h.logFor(c).Error("Blizzard ID is missing")
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing blizzard id"})
 		return
 	}
@@ -228,3 +308,242 @@ func (h *ProfileHandler) GetMainCharacter(c *gin.Context) {
 		"character": char,
 	})
 }
+
+func (h *ProfileHandler) GetMains(c *gin.Context) {
+	var req dto.MainLookupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logFor(c).WithError(err).Error("Invalid main lookup body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	result, err := h.uc.GetMains(c.Request.Context(), req.BlizzardIDs, req.Battletags)
+	if err != nil {
+		if strings.Contains(err.Error(), "no blizzard ids or battletags") ||
+			strings.Contains(err.Error(), "accounts requested") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *ProfileHandler) GetAccountSummary(c *gin.Context) {
+	tokenStr := c.GetHeader("Authorization")
+	if tokenStr == "" {
+		h.logFor(c).Error("Auth header is missing")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing auth header"})
+		return
+	}
+	token := strings.TrimPrefix(tokenStr, "Bearer ")
+	if token == "" {
+		h.logFor(c).Error("Invalid Bearer token")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Bearer token"})
+		return
+	}
+
+	user, err := h.blizzAd.GetUserData(c.Request.Context(), token)
+	if err != nil {
+		c.JSON(upstreamStatus(err, http.StatusBadRequest), gin.H{"error": "invalid access token"})
+		return
+	}
+
+	summary, err := h.uc.GetAccountSummary(c.Request.Context(), user.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "characters not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "characters not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func (h *ProfileHandler) GetMythicScoreLeaderboard(c *gin.Context) {
+	h.getGuildLeaderboard(c, entity.LeaderboardByMythicScore)
+}
+
+func (h *ProfileHandler) GetIlvlLeaderboard(c *gin.Context) {
+	h.getGuildLeaderboard(c, entity.LeaderboardByIlvl)
+}
+
+func (h *ProfileHandler) getGuildLeaderboard(c *gin.Context, sortBy string) {
+	guildID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || guildID <= 0 {
+		h.logFor(c).Error("Guild id is invalid")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid guild id"})
+		return
+	}
+
+	filter := entity.LeaderboardFilter{
+		GuildID: guildID,
+		SortBy:  sortBy,
+		Class:   c.Query("class"),
+		Role:    c.Query("role"),
+		Spec:    c.Query("spec"),
+		Faction: c.Query("faction"),
+	}
+
+	if minLevel := c.Query("min_level"); minLevel != "" {
+		filter.MinLevel, err = strconv.Atoi(minLevel)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid min_level"})
+			return
+		}
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+
+	if after := c.Query("after"); after != "" {
+		filter.After, err = entity.DecodeLeaderboardCursor(after)
+		if err != nil {
+			h.logFor(c).WithError(err).Warn("Invalid leaderboard cursor")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+	}
+
+	page, err := h.uc.GetGuildLeaderboard(c.Request.Context(), filter)
+	if err != nil {
+		if strings.Contains(err.Error(), "unsupported leaderboard") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *ProfileHandler) GetGuildEvents(c *gin.Context) {
+	guildID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || guildID <= 0 {
+		h.logFor(c).Error("Guild id is invalid")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid guild id"})
+		return
+	}
+
+	filter := entity.GuildEventFilter{
+		GuildID: guildID,
+		Type:    c.Query("type"),
+	}
+
+	if since := c.Query("since"); since != "" {
+		filter.Since, err = parseEventTime(since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since"})
+			return
+		}
+	}
+
+	if until := c.Query("until"); until != "" {
+		filter.Until, err = parseEventTime(until)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until"})
+			return
+		}
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+
+	if after := c.Query("after"); after != "" {
+		filter.After, err = entity.DecodeGuildEventCursor(after)
+		if err != nil {
+			h.logFor(c).WithError(err).Warn("Invalid guild event cursor")
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+			return
+		}
+	}
+
+	page, err := h.uc.GetGuildEvents(c.Request.Context(), filter)
+	if err != nil {
+		if strings.Contains(err.Error(), "unsupported guild event") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// parseEventTime accepts RFC 3339 timestamps or plain dates (midnight UTC).
+func parseEventTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
+
+func (h *ProfileHandler) Search(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		h.logFor(c).Error("Search query is missing")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing search query"})
+		return
+	}
+
+	filter := entity.SearchFilter{
+		Query: query,
+		Realm: c.Query("realm"),
+		Class: c.Query("class"),
+		Guild: c.Query("guild"),
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		var err error
+		filter.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+
+	result, err := h.uc.Search(c.Request.Context(), filter)
+	if err != nil {
+		if strings.Contains(err.Error(), "search query is shorter") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *ProfileHandler) GetBlizzardBudget(c *gin.Context) {
+	c.JSON(http.StatusOK, h.blizzAd.RateLimitBudget())
+}
+
+// upstreamStatus reports 503 when an upstream call was short-circuited by its
+// breaker, so clients can tell an outage apart from a bad request.
+func upstreamStatus(err error, fallback int) int {
+	if errors.Is(err, resilience.ErrCircuitOpen) {
+		return http.StatusServiceUnavailable
+	}
+	return fallback
+}
+
+func (h *ProfileHandler) logFor(c *gin.Context) *logrus.Entry {
+	return logger.FromContext(c.Request.Context(), h.log)
+}
@@ -1,24 +1,57 @@
 package handler
 
 import (
+	"net/http"
 	"profile-service/pkg/config"
+	"profile-service/pkg/metrics"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 func SetupRoutes(
 	router *gin.Engine,
 	h *ProfileHandler,
+	health *HealthHandler,
+	webhooks *WebhookHandler,
+	m *metrics.Metrics,
 	cfg *config.Config,
 	log *logrus.Logger,
 ) {
 	router.Use(gin.Recovery())
+	router.Use(otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		switch r.URL.Path {
+		case "/metrics", "/healthz", "/readyz":
+			return false
+		}
+		return true
+	})))
+	router.Use(RequestID(log))
+	router.Use(m.Middleware())
+
+	router.GET("/metrics", gin.WrapH(m.Handler()))
+	router.GET("/healthz", health.Liveness)
+	router.GET("/readyz", health.Readiness)
 
 	profile := router.Group("/profile")
 	profile.GET("/refresh", h.RefreshCharacters)
 	profile.GET("/characters", h.GetCharacters)
+	profile.GET("/summary", h.GetAccountSummary)
+	profile.GET("/search", h.Search)
+	profile.GET("/blizzard/budget", h.GetBlizzardBudget)
 	profile.POST("/main/set", h.SetMainCharacter)
+	profile.POST("/preferences", h.UpdatePreference)
 	profile.POST("/guild", h.GetGuild)
+	profile.GET("/guild/:id/leaderboard/mythic-score", h.GetMythicScoreLeaderboard)
+	profile.GET("/guild/:id/leaderboard/ilvl", h.GetIlvlLeaderboard)
+	profile.GET("/guild/:id/events", h.GetGuildEvents)
 	profile.POST("/main", h.GetMainCharacter)
+	profile.POST("/main/batch", h.GetMains)
+
+	hooks := profile.Group("/webhooks")
+	hooks.POST("", webhooks.CreateSubscription)
+	hooks.GET("", webhooks.ListSubscriptions)
+	hooks.DELETE("/:id", webhooks.DeleteSubscription)
+	hooks.GET("/:id/deliveries", webhooks.ListDeliveries)
 }
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"net/http"
+	"profile-service/internal/adapter/blizzard"
+	"profile-service/internal/entity"
+	"profile-service/internal/usecase"
+	"profile-service/pkg/dto"
+	logger "profile-service/pkg/log"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type WebhookHandler struct {
+	blizzAd blizzard.BlizzardRepository
+	uc      usecase.WebhookUsecase
+	log     *logrus.Logger
+}
+
+func NewWebhookHandler(
+	blizzAd blizzard.BlizzardRepository,
+	uc usecase.WebhookUsecase,
+	log *logrus.Logger,
+) *WebhookHandler {
+	return &WebhookHandler{
+		blizzAd: blizzAd,
+		uc:      uc,
+		log:     log,
+	}
+}
+
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	blizzardID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	var req dto.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logFor(c).WithError(err).Error("Invalid webhook subscription body")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	sub, err := h.uc.CreateSubscription(c.Request.Context(), blizzardID, entity.WebhookSubscription{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		GuildID:    req.GuildID,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid webhook subscription") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	// The secret is only ever returned here; receivers need it to verify
+	// the X-Webhook-Signature header.
+	c.JSON(http.StatusCreated, sub)
+}
+
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	blizzardID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	subs, err := h.uc.ListSubscriptions(c.Request.Context(), blizzardID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscriptions": subs})
+}
+
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	blizzardID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription id"})
+		return
+	}
+
+	if err := h.uc.DeleteSubscription(c.Request.Context(), blizzardID, id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "subscription not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	blizzardID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid subscription id"})
+		return
+	}
+
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+
+	deliveries, err := h.uc.ListDeliveries(c.Request.Context(), blizzardID, id, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// authorize resolves the caller's Blizzard account from the bearer token and
+// writes the error response itself when that fails.
+func (h *WebhookHandler) authorize(c *gin.Context) (string, bool) {
+	jwtToken := c.GetHeader("Authorization")
+	if jwtToken == "" {
+		h.logFor(c).Error("Auth header is missing")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "missing auth header"})
+		return "", false
+	}
+
+	token := strings.TrimPrefix(jwtToken, "Bearer ")
+	if token == "" {
+		h.logFor(c).Error("Invalid Bearer token")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid Bearer token"})
+		return "", false
+	}
+
+	user, err := h.blizzAd.GetUserData(c.Request.Context(), token)
+	if err != nil {
+		c.JSON(upstreamStatus(err, http.StatusUnauthorized), gin.H{"error": "invalid access token"})
+		return "", false
+	}
+
+	return user.ID, true
+}
+
+func (h *WebhookHandler) logFor(c *gin.Context) *logrus.Entry {
+	return logger.FromContext(c.Request.Context(), h.log)
+}
@@ -0,0 +1,7 @@
+package usecase
+
+import "context"
+
+type EventUsecase interface {
+	Run(ctx context.Context)
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"profile-service/internal/adapter/database"
+	"profile-service/internal/adapter/events"
+	"profile-service/pkg/config"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const eventPruneInterval = time.Hour
+
+type eventUsecase struct {
+	dbAd      database.PostgresRepository
+	publisher events.EventPublisher
+	cfg       atomic.Pointer[config.Config]
+	log       *logrus.Logger
+}
+
+// NewEventUsecase builds the relay that hands the domain events stored by
+// profileUsecase to publisher.
+func NewEventUsecase(
+	dbAd database.PostgresRepository,
+	publisher events.EventPublisher,
+	cfg *config.Config,
+	log *logrus.Logger,
+) *eventUsecase {
+	uc := &eventUsecase{
+		dbAd:      dbAd,
+		publisher: publisher,
+		log:       log,
+	}
+	uc.cfg.Store(cfg)
+	return uc
+}
+
+// ApplyConfig picks up reloaded relay settings on the next tick.
+func (uc *eventUsecase) ApplyConfig(cfg *config.Config) {
+	uc.cfg.Store(cfg)
+}
+
+// Run relays stored domain events until ctx is done, pruning published ones
+// past their retention on a separate ticker.
+func (uc *eventUsecase) Run(ctx context.Context) {
+	uc.log.Info("Event relay started")
+	defer uc.log.Info("Event relay stopped")
+
+	go uc.prune(ctx)
+
+	for {
+		cfg := uc.cfg.Load()
+
+		// A full batch means more events are waiting, so the next one goes
+		// out without waiting for the poll interval.
+		wait := cfg.Events.PollInterval
+		if uc.relay(ctx, cfg.Events.BatchSize) == cfg.Events.BatchSize {
+			wait = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// relay publishes one batch of unpublished events, oldest first, and marks the
+// published ones. It stops at the first failure so the rest keep their order
+// and are retried on the next tick. It returns how many were published.
+func (uc *eventUsecase) relay(ctx context.Context, limit int) int {
+	published := 0
+	if err := uc.dbAd.WithTx(ctx, func(repo database.PostgresRepository) error {
+		pending, err := repo.GetUnpublishedDomainEvents(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]string, 0, len(pending))
+		for _, event := range pending {
+			if err := uc.publisher.Publish(ctx, event); err != nil {
+				uc.log.WithError(err).WithField("event_id", event.ID).Warn("failed publish domain event, retrying later")
+				break
+			}
+			ids = append(ids, event.ID)
+		}
+
+		if err := repo.MarkDomainEventsPublished(ctx, ids); err != nil {
+			return err
+		}
+		published = len(ids)
+		return nil
+	}); err != nil {
+		uc.log.WithError(err).Warn("failed relay domain events")
+		return 0
+	}
+
+	return published
+}
+
+func (uc *eventUsecase) prune(ctx context.Context) {
+	ticker := time.NewTicker(eventPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		if retention := uc.cfg.Load().Events.Retention; retention > 0 {
+			if err := uc.dbAd.PruneDomainEvents(ctx, time.Now().Add(-retention)); err != nil {
+				uc.log.WithError(err).Warn("failed prune domain events")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
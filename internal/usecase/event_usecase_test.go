@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"profile-service/internal/adapter/events"
+	"profile-service/internal/entity"
+	"profile-service/pkg/config"
+	"reflect"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+const testRelayBatch = 100
+
+// relayEvents runs one relay pass of everything uc stored and returns what
+// reached the publisher.
+func relayEvents(t *testing.T, uc *profileUsecase) []entity.DomainEvent {
+	t.Helper()
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	publisher := events.NewMemoryPublisher()
+	NewEventUsecase(uc.dbAd, publisher, &config.Config{}, log).relay(context.Background(), testRelayBatch)
+	return publisher.Events()
+}
+
+func decodeEvent(t *testing.T, event entity.DomainEvent, eventType string, version int, data any) {
+	t.Helper()
+
+	if event.Type != eventType || event.Version != version || event.Source != entity.DomainEventSource {
+		t.Fatalf("event = %s v%d from %s, want %s v%d from %s", event.Type, event.Version, event.Source, eventType, version, entity.DomainEventSource)
+	}
+	if err := json.Unmarshal(event.Data, data); err != nil {
+		t.Fatalf("decode %s: %v", eventType, err)
+	}
+}
+
+func TestRefreshPublishesProfileRefreshed(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	batch := &entity.SyncBatch{
+		Characters: []entity.Character{testCharacter(testBlizzardID, 1, "Jaina"), testCharacter(testBlizzardID, 2, "Thrall")},
+		Unchanged:  3,
+	}
+	if err := uc.saveSync(ctx, testBlizzardID, batch); err != nil {
+		t.Fatalf("saveSync: %v", err)
+	}
+
+	published := relayEvents(t, uc)
+	if len(published) != 1 {
+		t.Fatalf("got %d events, want 1: %+v", len(published), published)
+	}
+
+	var got entity.ProfileRefreshedV1
+	decodeEvent(t, published[0], entity.EventProfileRefreshed, entity.ProfileRefreshedVersion, &got)
+	want := entity.ProfileRefreshedV1{BlizzardID: testBlizzardID, CharacterIDs: []int{1, 2}, Unchanged: 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("payload = %+v, want %+v", got, want)
+	}
+
+	if again := relayEvents(t, uc); len(again) != 0 {
+		t.Errorf("relayed %d events a second time, want them marked published", len(again))
+	}
+}
+
+func TestSetMainPublishesMainChanged(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	chars := []entity.Character{testCharacter(testBlizzardID, 1, "Jaina"), testCharacter(testBlizzardID, 2, "Thrall")}
+	if err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{Characters: chars}); err != nil {
+		t.Fatalf("saveSync: %v", err)
+	}
+	if err := uc.SetMain(ctx, testBlizzardID, "", "Jaina"); err != nil {
+		t.Fatalf("SetMain Jaina: %v", err)
+	}
+	relayEvents(t, uc)
+
+	if err := uc.SetMain(ctx, testBlizzardID, "", "Thrall"); err != nil {
+		t.Fatalf("SetMain Thrall: %v", err)
+	}
+	// Setting the same main again is not a change.
+	if err := uc.SetMain(ctx, testBlizzardID, "", "Thrall"); err != nil {
+		t.Fatalf("SetMain Thrall again: %v", err)
+	}
+
+	published := relayEvents(t, uc)
+	if len(published) != 1 {
+		t.Fatalf("got %d events, want 1: %+v", len(published), published)
+	}
+
+	var got entity.MainChangedV1
+	decodeEvent(t, published[0], entity.EventMainChanged, entity.MainChangedVersion, &got)
+	want := entity.MainChangedV1{BlizzardID: testBlizzardID, CharacterID: 2, Name: "Thrall", Realm: "Silvermoon", PreviousCharacterID: 1}
+	if got != want {
+		t.Errorf("payload = %+v, want %+v", got, want)
+	}
+}
+
+func TestRefreshPublishesGuildRosterUpdated(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	jaina := testCharacter(testBlizzardID, 1, "Jaina")
+	if err := uc.dbAd.ReplaceGuildMembers(ctx, 10, []entity.GuildMember{
+		{CharacterID: 50, Name: "Anduin", RealmSlug: "silvermoon", Rank: 5},
+	}); err != nil {
+		t.Fatalf("store baseline roster: %v", err)
+	}
+
+	if err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{
+		Characters: []entity.Character{jaina},
+		Guilds: []entity.Guild{{
+			CharacterID: jaina.CharacterID,
+			GuildID:     10,
+			Name:        "Test Guild",
+			NameSlug:    "test-guild",
+			Realm:       "Silvermoon",
+			RealmSlug:   "silvermoon",
+			Faction:     "Alliance",
+		}},
+	}); err != nil {
+		t.Fatalf("saveSync: %v", err)
+	}
+
+	var rosterEvents []entity.DomainEvent
+	for _, event := range relayEvents(t, uc) {
+		if event.Type == entity.EventGuildRosterUpdated {
+			rosterEvents = append(rosterEvents, event)
+		}
+	}
+	if len(rosterEvents) != 1 {
+		t.Fatalf("got %d roster events, want 1", len(rosterEvents))
+	}
+
+	var got entity.GuildRosterUpdatedV1
+	decodeEvent(t, rosterEvents[0], entity.EventGuildRosterUpdated, entity.GuildRosterUpdatedVersion, &got)
+	want := entity.GuildRosterUpdatedV1{
+		GuildID:     10,
+		NameSlug:    "test-guild",
+		RealmSlug:   "silvermoon",
+		Members:     2,
+		Joined:      []int{1},
+		Left:        []int{},
+		RankChanged: []int{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("payload = %+v, want %+v", got, want)
+	}
+}
+
+type failingPublisher struct{}
+
+func (failingPublisher) Publish(context.Context, entity.DomainEvent) error {
+	return errors.New("broker down")
+}
+
+func TestRelayRetriesEventsThatFailedToPublish(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	if err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{Characters: []entity.Character{testCharacter(testBlizzardID, 1, "Jaina")}}); err != nil {
+		t.Fatalf("saveSync: %v", err)
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	if n := NewEventUsecase(uc.dbAd, failingPublisher{}, &config.Config{}, log).relay(ctx, testRelayBatch); n != 0 {
+		t.Fatalf("relay marked %d events published while the publisher failed", n)
+	}
+
+	if published := relayEvents(t, uc); len(published) != 1 || published[0].Type != entity.EventProfileRefreshed {
+		t.Errorf("retry published %+v, want the refresh event", published)
+	}
+}
+
+// flakyPublisher publishes the first ok events and fails every call after.
+type flakyPublisher struct {
+	ok        int
+	published []entity.DomainEvent
+}
+
+func (p *flakyPublisher) Publish(_ context.Context, event entity.DomainEvent) error {
+	if len(p.published) == p.ok {
+		return errors.New("broker down")
+	}
+	p.published = append(p.published, event)
+	return nil
+}
+
+func TestRelayStopsAtTheFirstFailedEvent(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	// Every refresh stores one profile.refreshed event.
+	for i := 0; i < 3; i++ {
+		batch := &entity.SyncBatch{Characters: []entity.Character{testCharacter(testBlizzardID, 1, "Jaina")}}
+		if err := uc.saveSync(ctx, testBlizzardID, batch); err != nil {
+			t.Fatalf("saveSync: %v", err)
+		}
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	flaky := &flakyPublisher{ok: 1}
+	if n := NewEventUsecase(uc.dbAd, flaky, &config.Config{}, log).relay(ctx, testRelayBatch); n != 1 {
+		t.Fatalf("relay marked %d events published, want only the one before the failure", n)
+	}
+
+	// The events after the failure stay pending and keep their order.
+	retried := relayEvents(t, uc)
+	if len(retried) != 2 {
+		t.Fatalf("retry published %d events, want 2", len(retried))
+	}
+	all := append(flaky.published, retried...)
+	for i := 1; i < len(all); i++ {
+		if all[i].OccurredAt.Before(all[i-1].OccurredAt) {
+			t.Errorf("event %d published before an older one", i)
+		}
+	}
+	if again := relayEvents(t, uc); len(again) != 0 {
+		t.Errorf("published events relayed again: %d", len(again))
+	}
+}
+
+func TestFailedSyncStoresNoEvents(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	// The guild row references a character that is not saved, so the sync
+	// transaction fails after the characters were written.
+	err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{
+		Characters: []entity.Character{testCharacter(testBlizzardID, 1, "Jaina")},
+		Guilds:     []entity.Guild{{CharacterID: 999, GuildID: 10, Name: "Test Guild", NameSlug: "test-guild", Realm: "Silvermoon", RealmSlug: "silvermoon"}},
+	})
+	if err == nil {
+		t.Fatal("saveSync succeeded, want the guild foreign key error")
+	}
+
+	if published := relayEvents(t, uc); len(published) != 0 {
+		t.Errorf("relayed %d events of a rolled back sync", len(published))
+	}
+}
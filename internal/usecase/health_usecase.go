@@ -0,0 +1,10 @@
+package usecase
+
+import (
+	"context"
+	"profile-service/internal/entity"
+)
+
+type HealthUsecase interface {
+	Readiness(ctx context.Context) *entity.Readiness
+}
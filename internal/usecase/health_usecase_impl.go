@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"profile-service/internal/adapter/blizzard"
+	"profile-service/internal/adapter/database"
+	"profile-service/internal/entity"
+	logger "profile-service/pkg/log"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const dependencyCheckTimeout = 3 * time.Second
+
+type healthUsecase struct {
+	dbAd    database.PostgresRepository
+	blizzAd blizzard.BlizzardRepository
+	log     *logrus.Logger
+}
+
+func NewHealthUsecase(
+	dbAd database.PostgresRepository,
+	blizzAd blizzard.BlizzardRepository,
+	log *logrus.Logger,
+) *healthUsecase {
+	return &healthUsecase{
+		dbAd:    dbAd,
+		blizzAd: blizzAd,
+		log:     log,
+	}
+}
+
+type dependencyCheck struct {
+	name     string
+	critical bool
+	check    func(ctx context.Context) error
+}
+
+func (uc *healthUsecase) Readiness(ctx context.Context) *entity.Readiness {
+	checks := []dependencyCheck{
+		{name: "postgres", critical: true, check: uc.dbAd.Ping},
+		{name: "migrations", critical: true, check: uc.checkMigrations},
+		{name: "auth_service", critical: false, check: uc.blizzAd.PingAuth},
+		{name: "blizzard", critical: false, check: uc.blizzAd.PingAPI},
+	}
+
+	deps := make([]entity.DependencyStatus, len(checks))
+	wg := sync.WaitGroup{}
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			deps[i] = uc.run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	readiness := &entity.Readiness{Status: entity.HealthUp, Dependencies: deps}
+	for _, dep := range deps {
+		if dep.Status == entity.HealthUp {
+			continue
+		}
+		if dep.Critical {
+			readiness.Status = entity.HealthDown
+			break
+		}
+		readiness.Status = entity.HealthDegraded
+	}
+
+	return readiness
+}
+
+func (uc *healthUsecase) run(ctx context.Context, c dependencyCheck) entity.DependencyStatus {
+	checkCtx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.check(checkCtx)
+
+	status := entity.DependencyStatus{
+		Name:      c.name,
+		Status:    entity.HealthUp,
+		Critical:  c.critical,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		uc.logFor(ctx).WithError(err).WithField("dependency", c.name).Warn("readiness check failed")
+		status.Status = entity.HealthDown
+		status.Error = err.Error()
+	}
+
+	return status
+}
+
+func (uc *healthUsecase) checkMigrations(ctx context.Context) error {
+	version, dirty, err := uc.dbAd.MigrationVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	return nil
+}
+
+func (uc *healthUsecase) logFor(ctx context.Context) *logrus.Entry {
+	return logger.FromContext(ctx, uc.log)
+}
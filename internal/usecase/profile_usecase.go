@@ -6,9 +6,18 @@ import (
 )
 
 type ProfileUsecase interface {
-	GetCharacters(ctx context.Context, blizzardID, accessToken, jwtToken string) ([]entity.Character, error)
+	GetCharacters(ctx context.Context, blizzardID, accessToken, jwtToken string) (*entity.Profile, error)
+	GetStoredCharacters(ctx context.Context, blizzardID string) (*entity.Profile, error)
 	RefreshCharacters(ctx context.Context, blizzardID, accessToken, jwtToken string) error
-	SetMain(ctx context.Context, blizzardID, charName string) error
-	GetGuildByName(ctx context.Context, nameSlug string) (*entity.Guild, error)
+	SyncAccount(ctx context.Context, blizzardID string) (*entity.SyncBatch, error)
+	SyncGuild(ctx context.Context, realm, nameSlug string) (*entity.GuildRoster, error)
+	SetMain(ctx context.Context, blizzardID, realm, charName string) error
+	UpdatePreference(ctx context.Context, blizzardID, realm, charName string, pref entity.Preference) error
+	GetGuildByName(ctx context.Context, name, realm string) (*entity.Guild, error)
 	GetMainCharacterByBlizzardID(ctx context.Context, blizzardID string) (*entity.Character, error)
+	GetMains(ctx context.Context, blizzardIDs, battletags []string) (*entity.MainLookupResult, error)
+	GetAccountSummary(ctx context.Context, blizzardID string) (*entity.AccountSummary, error)
+	GetGuildLeaderboard(ctx context.Context, filter entity.LeaderboardFilter) (*entity.LeaderboardPage, error)
+	GetGuildEvents(ctx context.Context, filter entity.GuildEventFilter) (*entity.GuildEventPage, error)
+	Search(ctx context.Context, filter entity.SearchFilter) (*entity.SearchResult, error)
 }
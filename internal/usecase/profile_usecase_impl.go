@@ -2,157 +2,861 @@ package usecase
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
 	"profile-service/internal/adapter/blizzard"
 	"profile-service/internal/adapter/database"
 	"profile-service/internal/entity"
+	"profile-service/pkg/config"
+	dbpool "profile-service/pkg/db"
 	"profile-service/pkg/errors"
+	logger "profile-service/pkg/log"
+	"profile-service/pkg/slug"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	maxNoteLength           = 500
+	defaultLeaderboardLimit = 25
+	maxLeaderboardLimit     = 100
+	defaultGuildEventLimit  = 50
+	maxGuildEventLimit      = 200
+	minSearchQueryLength    = 2
+	defaultSearchLimit      = 20
+	maxSearchLimit          = 50
+	maxMainLookup           = 100
+	realmIndexTTL           = 24 * time.Hour
+)
+
+var tracer = otel.Tracer("profile-service/internal/usecase")
+
 type profileUsecase struct {
 	dbAd    database.PostgresRepository
 	blizzAd blizzard.BlizzardRepository
+	realms  *slug.RealmIndex
+	cfg     atomic.Pointer[config.Config]
 	log     *logrus.Logger
 }
 
 func NewProfileUsecase(
 	dbAd database.PostgresRepository,
 	blizzAd blizzard.BlizzardRepository,
+	realms *slug.RealmIndex,
+	cfg *config.Config,
 	log *logrus.Logger,
 ) *profileUsecase {
-	return &profileUsecase{
+	uc := &profileUsecase{
 		dbAd:    dbAd,
 		blizzAd: blizzAd,
+		realms:  realms,
 		log:     log,
 	}
+	uc.cfg.Store(cfg)
+	return uc
+}
+
+// ApplyConfig swaps in a reloaded config; refresh TTL and feature toggles
+// take effect on the next request.
+func (uc *profileUsecase) ApplyConfig(cfg *config.Config) {
+	uc.cfg.Store(cfg)
 }
 
-func (uc *profileUsecase) GetCharacters(ctx context.Context, blizzardID, accessToken, jwtToken string) ([]entity.Character, error) {
+func (uc *profileUsecase) GetCharacters(ctx context.Context, blizzardID, accessToken, jwtToken string) (*entity.Profile, error) {
+	ctx, span := tracer.Start(ctx, "usecase.GetCharacters", trace.WithAttributes(attribute.String("blizzard_id", blizzardID)))
+	defer span.End()
+
 	if accessToken == "" || blizzardID == "" {
-		uc.log.Warn("id or access token is empty")
+		uc.logFor(ctx).Warn("id or access token is empty")
 		return nil, fmt.Errorf("id or access token is empty")
 	}
 
-	var dbChars []entity.Character
-	var err error
-	if blizzardID != "" {
-		dbChars, err = uc.dbAd.GetCharacters(ctx, blizzardID)
+	dbChars, err := uc.dbAd.GetCharacters(ctx, blizzardID)
+	if err != nil {
+		uc.logFor(ctx).WithError(err).WithField("blizzard_id", blizzardID).Warn("failed get characters from DB")
+	}
+
+	if len(dbChars) > 0 {
+		uc.logFor(ctx).Debugf("Found %d characters in DB for blizzard_id %s", len(dbChars), blizzardID)
+
+		state, err := uc.dbAd.GetSyncState(ctx, blizzardID)
 		if err != nil {
-			uc.log.WithError(err).WithField("blizzard_id", blizzardID).Warn("failed get characters from DB")
+			uc.logFor(ctx).WithError(err).WithField("blizzard_id", blizzardID).Warn("failed get sync state from DB")
+			state = &entity.SyncState{BlizzardID: blizzardID}
+		}
+
+		cached := &entity.Profile{Characters: dbChars, SyncedAt: state.SyncedAt}
+		if time.Since(state.SyncedAt) < uc.cfg.Load().Profile.RefreshTTL {
+			return cached, nil
+		}
+
+		if !uc.blizzAd.Available() {
+			uc.logFor(ctx).WithField("blizzard_id", blizzardID).Warn("Blizzard API unavailable, serving cached characters")
+			cached.Degraded = true
+			return cached, nil
+		}
+
+		if err := uc.RefreshCharacters(ctx, blizzardID, accessToken, jwtToken); err != nil {
+			uc.logFor(ctx).WithError(err).WithField("blizzard_id", blizzardID).Warn("failed refresh stale characters, serving cached characters")
+			cached.Degraded = true
+			return cached, nil
 		}
+
+		return uc.loadProfile(ctx, blizzardID)
 	}
+	uc.logFor(ctx).Debug("No characters found in DB, fetching from Blizzard API")
 
-	if len(dbChars) > 0 {
-		uc.log.Debugf("Found %d characters in DB for blizzard_id %s", len(dbChars), blizzardID)
-		return dbChars, nil
+	if err := uc.RefreshCharacters(ctx, blizzardID, accessToken, jwtToken); err != nil {
+		return nil, err
 	}
-	uc.log.Debug("No characters found in DB or blizzardID not provided, fetching from Blizzard API")
 
-	blizzChars, guilds, err := uc.blizzAd.GetCharacters(ctx, accessToken, jwtToken)
+	return uc.loadProfile(ctx, blizzardID)
+}
+
+// GetStoredCharacters serves an account from the database only, for callers
+// that have no user token to refresh it with.
+func (uc *profileUsecase) GetStoredCharacters(ctx context.Context, blizzardID string) (*entity.Profile, error) {
+	if blizzardID == "" {
+		uc.logFor(ctx).Warn("blizzard id is empty")
+		return nil, errors.NewAppError("blizzard id is empty", nil)
+	}
+
+	chars, err := uc.dbAd.GetCharacters(ctx, blizzardID)
 	if err != nil {
-		uc.log.WithError(err).Error("failed fetch characters from Blizzard API")
+		uc.logFor(ctx).WithError(err).WithField("blizzard_id", blizzardID).Error("failed get characters from DB")
 		return nil, err
 	}
-	uc.log.Debugf("Fetched %d characters from Blizzard API", len(blizzChars))
+	if len(chars) == 0 {
+		return nil, errors.NewAppError("characters not found", nil)
+	}
 
-	if len(blizzChars) > 0 {
-		if err := uc.dbAd.SaveCharacters(ctx, blizzChars); err != nil {
-			uc.log.WithError(err).Error("Failed save characters")
-			return nil, err
-		}
+	profile := &entity.Profile{Characters: chars}
+	if state, err := uc.dbAd.GetSyncState(ctx, blizzardID); err == nil {
+		profile.SyncedAt = state.SyncedAt
+	}
 
-		if err := uc.dbAd.SaveGuilds(ctx, guilds); err != nil {
-			uc.log.WithError(err).Error("Failed save guilds")
-			return nil, err
-		}
-		uc.log.Infof("Saved %d characters to DB", len(blizzChars))
-	} else {
-		uc.log.Info("BlizzardID is empty in fetched characters")
+	return profile, nil
+}
+
+// loadProfile reads an account back right after RefreshCharacters saved it,
+// so it reads from the primary rather than a replica that may lag behind.
+func (uc *profileUsecase) loadProfile(ctx context.Context, blizzardID string) (*entity.Profile, error) {
+	ctx = dbpool.WithPrimary(ctx)
+
+	chars, err := uc.dbAd.GetCharacters(ctx, blizzardID)
+	if err != nil {
+		uc.logFor(ctx).WithError(err).WithField("blizzard_id", blizzardID).Error("failed get characters from DB")
+		return nil, err
 	}
 
-	return blizzChars, nil
+	state, err := uc.dbAd.GetSyncState(ctx, blizzardID)
+	if err != nil {
+		uc.logFor(ctx).WithError(err).WithField("blizzard_id", blizzardID).Error("failed get sync state from DB")
+		return nil, err
+	}
+
+	return &entity.Profile{Characters: chars, SyncedAt: state.SyncedAt}, nil
 }
 
-func (uc *profileUsecase) GetGuildByName(ctx context.Context, nameSlug string) (*entity.Guild, error) {
+func (uc *profileUsecase) GetGuildByName(ctx context.Context, name, realm string) (*entity.Guild, error) {
+	nameSlug := slug.Make(name)
 	if nameSlug == "" {
-		uc.log.Warn("guild name is empty")
+		uc.logFor(ctx).Warn("guild name is empty")
 		return nil, errors.NewAppError("guild name is empty", nil)
 	}
 
-	guild, err := uc.dbAd.GetGuildByName(ctx, nameSlug)
+	realmSlug := ""
+	if realm != "" {
+		realmSlug = uc.realms.Slug(realm)
+	}
+
+	guild, err := uc.dbAd.GetGuildByName(ctx, nameSlug, realmSlug)
 	if err != nil {
-		uc.log.WithError(err).Error("failed get guild by name")
+		uc.logFor(ctx).WithError(err).Error("failed get guild by name")
 		return nil, err
 	}
 
-	uc.log.WithField("guild", nameSlug).Info("Get guild succeded")
+	uc.logFor(ctx).WithField("guild", nameSlug).Info("Get guild succeded")
 	return guild, nil
 }
 
 func (uc *profileUsecase) RefreshCharacters(ctx context.Context, blizzardID, accessToken, jwtToken string) error {
+	ctx, span := tracer.Start(ctx, "usecase.RefreshCharacters", trace.WithAttributes(attribute.String("blizzard_id", blizzardID)))
+	defer span.End()
+
 	if accessToken == "" || blizzardID == "" {
-		uc.log.Warn("id or access token is empty")
+		uc.logFor(ctx).Warn("id or access token is empty")
 		return errors.NewAppError("id or access token is empty", nil)
 	}
 
-	blizzChars, guilds, err := uc.blizzAd.GetCharacters(ctx, accessToken, jwtToken)
+	uc.syncRealms(ctx, accessToken)
+
+	conditional := uc.cfg.Load().Features.ConditionalRequests
+	if conditional {
+		state, err := uc.dbAd.GetSyncState(ctx, blizzardID)
+		conditional = err == nil && state.Characters > 0
+	}
+
+	batch, err := uc.blizzAd.GetCharacters(ctx, accessToken, jwtToken, conditional)
+	if err != nil {
+		uc.logFor(ctx).WithError(err).Error("failed fetch characters from Blizzard API")
+		return err
+	}
+	uc.logFor(ctx).Debugf("Fetched %d characters from Blizzard API, %d unchanged, %d failed", len(batch.Characters), batch.Unchanged, batch.Failed)
+
+	return uc.saveSync(ctx, blizzardID, batch)
+}
+
+// SyncAccount refreshes the characters already stored for blizzardID using an
+// application token. Characters created or deleted on the account since the
+// last user-initiated refresh are not picked up.
+func (uc *profileUsecase) SyncAccount(ctx context.Context, blizzardID string) (*entity.SyncBatch, error) {
+	ctx, span := tracer.Start(ctx, "usecase.SyncAccount", trace.WithAttributes(attribute.String("blizzard_id", blizzardID)))
+	defer span.End()
+
+	if blizzardID == "" {
+		uc.logFor(ctx).Warn("blizzard id is empty")
+		return nil, errors.NewAppError("blizzard id is empty", nil)
+	}
+
+	known, err := uc.dbAd.GetCharacters(ctx, blizzardID)
+	if err != nil {
+		uc.logFor(ctx).WithError(err).Error("failed get characters from DB")
+		return nil, err
+	}
+	if len(known) == 0 {
+		uc.logFor(ctx).WithField("blizzard_id", blizzardID).Warn("no stored characters to sync")
+		return nil, errors.NewAppError("no stored characters for this account", nil)
+	}
+
+	token, err := uc.blizzAd.GetClientToken(ctx)
+	if err != nil {
+		uc.logFor(ctx).WithError(err).Error("failed get client token")
+		return nil, err
+	}
+
+	uc.syncRealms(ctx, token)
+
+	batch, err := uc.blizzAd.GetKnownCharacters(ctx, token, known, uc.cfg.Load().Features.ConditionalRequests)
+	if err != nil {
+		uc.logFor(ctx).WithError(err).Error("failed fetch characters from Blizzard API")
+		return nil, err
+	}
+
+	if err := uc.saveSync(ctx, blizzardID, batch); err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+func (uc *profileUsecase) SyncGuild(ctx context.Context, realm, nameSlug string) (*entity.GuildRoster, error) {
+	ctx, span := tracer.Start(ctx, "usecase.SyncGuild", trace.WithAttributes(attribute.String("guild", nameSlug)))
+	defer span.End()
+
+	if realm == "" || nameSlug == "" {
+		uc.logFor(ctx).Warn("realm or guild slug is empty")
+		return nil, errors.NewAppError("realm or guild slug is empty", nil)
+	}
+
+	token, err := uc.blizzAd.GetClientToken(ctx)
 	if err != nil {
-		uc.log.WithError(err).Error("failed fetch characters from Blizzard API")
+		uc.logFor(ctx).WithError(err).Error("failed get client token")
+		return nil, err
+	}
+
+	uc.syncRealms(ctx, token)
+
+	roster, err := uc.blizzAd.GetGuildRoster(ctx, token, uc.realms.Slug(realm), slug.Make(nameSlug))
+	if err != nil {
+		uc.logFor(ctx).WithError(err).Error("failed fetch guild roster from Blizzard API")
+		return nil, err
+	}
+
+	var (
+		changes  []entity.GuildEvent
+		baseline bool
+	)
+	if err := uc.dbAd.WithTx(ctx, func(repo database.PostgresRepository) error {
+		stored, err := repo.GetGuildMembers(ctx, roster.GuildID)
+		if err != nil {
+			return err
+		}
+		baseline = len(stored) == 0
+
+		// The first sync of a guild only records the baseline roster;
+		// reporting every member as a join would drown the real changes.
+		if baseline {
+			if err := repo.ReplaceGuildMembers(ctx, roster.GuildID, roster.Members); err != nil {
+				return err
+			}
+		} else {
+			changes = entity.DiffGuildRoster(roster.GuildID, stored, roster.Members, time.Now().UTC())
+			if err := uc.saveRosterChanges(ctx, repo, roster.GuildID, changes, roster.Members); err != nil {
+				return err
+			}
+		}
+
+		return saveDomainEvent(ctx, repo, entity.EventGuildRosterUpdated, entity.GuildRosterUpdatedVersion,
+			guildRosterUpdate(roster.GuildID, roster.NameSlug, roster.RealmSlug, len(roster.Members), changes, baseline))
+	}); err != nil {
+		uc.logFor(ctx).WithError(err).Error("failed save guild roster")
+		return nil, err
+	}
+
+	uc.logFor(ctx).WithFields(logrus.Fields{
+		"guild":  roster.NameSlug,
+		"events": len(changes),
+	}).Infof("Guild roster synced: %d members", len(roster.Members))
+	return roster, nil
+}
+
+// saveRosterChanges records the roster changes of guildID as guild events and
+// webhooks, then stores current as its roster.
+func (uc *profileUsecase) saveRosterChanges(ctx context.Context, repo database.PostgresRepository, guildID int, changes []entity.GuildEvent, current []entity.GuildMember) error {
+	if err := repo.SaveGuildEvents(ctx, changes); err != nil {
 		return err
 	}
 
-	if err := uc.dbAd.SaveCharacters(ctx, blizzChars); err != nil {
-		uc.log.WithError(err).Error("failed save characters")
+	outbox, err := entity.GuildWebhookEvents(changes)
+	if err != nil {
+		return errors.NewAppError("failed build guild events", err)
+	}
+	if err := repo.SaveOutboxEvents(ctx, outbox); err != nil {
 		return err
 	}
 
-	if err := uc.dbAd.SaveGuilds(ctx, guilds); err != nil {
-		uc.log.WithError(err).Error("Failed save guilds")
+	return repo.ReplaceGuildMembers(ctx, guildID, current)
+}
+
+// saveAccountRosters updates the stored roster of every guild the refreshed
+// characters were or now are in. Guilds without a stored roster are left to
+// SyncGuild, which records their baseline.
+func (uc *profileUsecase) saveAccountRosters(ctx context.Context, repo database.PostgresRepository, batch *entity.SyncBatch) error {
+	ids := make([]int, 0, len(batch.Characters))
+	for _, c := range batch.Characters {
+		ids = append(ids, c.CharacterID)
+	}
+	previous, err := repo.GetCharacterGuilds(ctx, ids)
+	if err != nil {
+		uc.logFor(ctx).WithError(err).Error("failed load stored guilds")
 		return err
 	}
 
+	touched := make(map[int]entity.Guild)
+	for _, g := range previous {
+		touched[g.GuildID] = g
+	}
+	for _, g := range batch.Guilds {
+		touched[g.GuildID] = g
+	}
+	guildIDs := make([]int, 0, len(touched))
+	for id := range touched {
+		guildIDs = append(guildIDs, id)
+	}
+	// Locking rosters in a fixed order keeps concurrent refreshes of
+	// guildmates from deadlocking.
+	sort.Ints(guildIDs)
+
+	for _, guildID := range guildIDs {
+		stored, err := repo.GetGuildMembers(ctx, guildID)
+		if err != nil {
+			uc.logFor(ctx).WithError(err).Error("failed load stored guild roster")
+			return err
+		}
+		if len(stored) == 0 {
+			continue
+		}
+
+		current := entity.ApplyAccountMembers(guildID, stored, batch.Characters, batch.Guilds)
+		changes := entity.DiffGuildRoster(guildID, stored, current, time.Now().UTC())
+		if len(changes) == 0 {
+			continue
+		}
+
+		if err := uc.saveRosterChanges(ctx, repo, guildID, changes, current); err != nil {
+			uc.logFor(ctx).WithError(err).Error("failed save guild roster")
+			return err
+		}
+
+		g := touched[guildID]
+		if err := saveDomainEvent(ctx, repo, entity.EventGuildRosterUpdated, entity.GuildRosterUpdatedVersion,
+			guildRosterUpdate(guildID, g.NameSlug, g.RealmSlug, len(current), changes, false)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (uc *profileUsecase) SetMain(ctx context.Context, blizzardID, charName string) error {
+func guildRosterUpdate(guildID int, nameSlug, realmSlug string, members int, changes []entity.GuildEvent, baseline bool) entity.GuildRosterUpdatedV1 {
+	update := entity.GuildRosterUpdatedV1{
+		GuildID:      guildID,
+		NameSlug:     nameSlug,
+		RealmSlug:    realmSlug,
+		Members:      members,
+		Joined:       make([]int, 0),
+		Left:         make([]int, 0),
+		RankChanged:  make([]int, 0),
+		BaselineSync: baseline,
+	}
+	for _, e := range changes {
+		switch e.Type {
+		case entity.GuildEventJoin:
+			update.Joined = append(update.Joined, e.CharacterID)
+		case entity.GuildEventLeave:
+			update.Left = append(update.Left, e.CharacterID)
+		case entity.GuildEventRankChange:
+			update.RankChanged = append(update.RankChanged, e.CharacterID)
+		}
+	}
+	return update
+}
+
+// saveDomainEvent stores a domain event in the transaction of repo, so it is
+// relayed exactly when the change it describes commits.
+func saveDomainEvent(ctx context.Context, repo database.PostgresRepository, eventType string, version int, data any) error {
+	event, err := entity.NewDomainEvent(eventType, version, data)
+	if err != nil {
+		return errors.NewAppError("failed build domain event", err)
+	}
+	return repo.SaveDomainEvents(ctx, []entity.DomainEvent{event})
+}
+
+func (uc *profileUsecase) GetGuildEvents(ctx context.Context, filter entity.GuildEventFilter) (*entity.GuildEventPage, error) {
+	if filter.GuildID <= 0 {
+		uc.logFor(ctx).Error("guild id is empty")
+		return nil, errors.NewAppError("guild id is empty", nil)
+	}
+
+	if filter.Type != "" && !entity.IsGuildEventType(filter.Type) {
+		uc.logFor(ctx).WithField("type", filter.Type).Warn("unsupported guild event type")
+		return nil, errors.NewAppError("unsupported guild event type", nil)
+	}
+
+	if !filter.Since.IsZero() && !filter.Until.IsZero() && !filter.Since.Before(filter.Until) {
+		uc.logFor(ctx).Warn("guild event range is empty")
+		return nil, errors.NewAppError("unsupported guild event range: since must be before until", nil)
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultGuildEventLimit
+	}
+	if filter.Limit > maxGuildEventLimit {
+		filter.Limit = maxGuildEventLimit
+	}
+
+	page, err := uc.dbAd.GetGuildEvents(ctx, filter)
+	if err != nil {
+		uc.logFor(ctx).WithError(err).WithField("guild_id", filter.GuildID).Error("failed get guild events")
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// syncRealms refreshes the realm index from Blizzard once it is empty or stale.
+// Failures are logged only: slug lookups fall back to the generic rules.
+func (uc *profileUsecase) syncRealms(ctx context.Context, accessToken string) {
+	if uc.realms.Len() > 0 && time.Since(uc.realms.LoadedAt()) < realmIndexTTL {
+		return
+	}
+
+	realms, err := uc.blizzAd.GetRealmIndex(ctx, accessToken)
+	if err != nil {
+		uc.logFor(ctx).WithError(err).Warn("failed fetch realm index")
+		return
+	}
+
+	if err := uc.dbAd.SaveRealms(ctx, realms); err != nil {
+		uc.logFor(ctx).WithError(err).Warn("failed save realm index")
+		return
+	}
+
+	uc.realms.Load(realms)
+	uc.logFor(ctx).Infof("Realm index refreshed: %d realms", len(realms))
+}
+
+func (uc *profileUsecase) saveSync(ctx context.Context, blizzardID string, batch *entity.SyncBatch) error {
+	ctx, span := tracer.Start(ctx, "usecase.saveSync")
+	defer span.End()
+
+	return uc.dbAd.WithTx(ctx, func(repo database.PostgresRepository) error {
+		var stored []entity.Character
+		if len(batch.Characters) > 0 {
+			var err error
+			stored, err = repo.GetCharacters(ctx, blizzardID)
+			if err != nil {
+				uc.logFor(ctx).WithError(err).Error("failed load stored characters")
+				return err
+			}
+
+			events, err := entity.CharacterWebhookEvents(blizzardID, stored, batch.Characters, uc.cfg.Load().Webhooks.ScoreThresholds)
+			if err != nil {
+				return errors.NewAppError("failed build character events", err)
+			}
+			if err := repo.SaveOutboxEvents(ctx, events); err != nil {
+				uc.logFor(ctx).WithError(err).Error("failed save character events")
+				return err
+			}
+		}
+
+		if err := repo.SaveCharacters(ctx, batch.Characters); err != nil {
+			uc.logFor(ctx).WithError(err).Error("failed save characters")
+			return err
+		}
+
+		if err := repo.SaveCharacterSnapshots(ctx, entity.ChangedSnapshots(stored, batch.Characters)); err != nil {
+			uc.logFor(ctx).WithError(err).Error("failed save character snapshots")
+			return err
+		}
+
+		if err := uc.saveAccountRosters(ctx, repo, batch); err != nil {
+			return err
+		}
+
+		if err := repo.DeleteCharacterGuilds(ctx, batch.Guildless()); err != nil {
+			uc.logFor(ctx).WithError(err).Error("failed delete left guilds")
+			return err
+		}
+
+		if err := repo.SaveGuilds(ctx, batch.Guilds); err != nil {
+			uc.logFor(ctx).WithError(err).Error("Failed save guilds")
+			return err
+		}
+
+		if err := repo.SaveHTTPValidators(ctx, batch.Validators); err != nil {
+			uc.logFor(ctx).WithError(err).Error("failed save http validators")
+			return err
+		}
+
+		if err := repo.SaveSyncState(ctx, blizzardID, len(batch.Characters)+batch.Unchanged+batch.Failed); err != nil {
+			uc.logFor(ctx).WithError(err).Error("failed save sync state")
+			return err
+		}
+
+		ids := make([]int, 0, len(batch.Characters))
+		for _, char := range batch.Characters {
+			ids = append(ids, char.CharacterID)
+		}
+		return saveDomainEvent(ctx, repo, entity.EventProfileRefreshed, entity.ProfileRefreshedVersion, entity.ProfileRefreshedV1{
+			BlizzardID:   blizzardID,
+			CharacterIDs: ids,
+			Unchanged:    batch.Unchanged,
+		})
+	})
+}
+
+// SetMain marks the account's character named charName as its main. realm may
+// be empty as long as the name is unique across the account's realms.
+func (uc *profileUsecase) SetMain(ctx context.Context, blizzardID, realm, charName string) error {
 	if blizzardID == "" || charName == "" {
-		uc.log.Error("blizzardID or charcater name is empty")
+		uc.logFor(ctx).Error("blizzardID or charcater name is empty")
 		return errors.NewAppError("blizzardID or charcater name is empty", nil)
 	}
 
-	_, err := uc.dbAd.GetCharacterByName(ctx, charName)
+	realmSlug := uc.characterRealmSlug(realm)
+	_, err := uc.dbAd.GetCharacterByName(dbpool.WithPrimary(ctx), blizzardID, realmSlug, charName)
 	if err != nil {
-		uc.log.WithError(err).Errorf("character %s not found", charName)
+		uc.logFor(ctx).WithError(err).Errorf("character %s not found", charName)
 		return err
 	}
 
-	if err := uc.dbAd.SetMainCharacter(ctx, blizzardID, charName); err != nil {
+	var previous, current *entity.Character
+	if err := uc.dbAd.WithTx(ctx, func(repo database.PostgresRepository) error {
+		var err error
+		previous, err = repo.GetMainCharacterByBlizzardID(ctx, blizzardID)
+		if err != nil && !stderrors.Is(err, database.ErrCharacterNotFound) {
+			return err
+		}
+
+		if err := repo.SetMainCharacter(ctx, blizzardID, realmSlug, charName); err != nil {
+			return err
+		}
+
+		current, err = repo.GetMainCharacterByBlizzardID(ctx, blizzardID)
+		if err != nil {
+			return err
+		}
+		if previous != nil && previous.CharacterID == current.CharacterID {
+			return nil
+		}
+
+		change := entity.MainChanged{
+			CharacterID: current.CharacterID,
+			Name:        current.Name,
+			Realm:       current.Realm,
+		}
+		if previous != nil {
+			change.OldCharacterID = previous.CharacterID
+			change.OldName = previous.Name
+		}
+
+		event, err := entity.NewOutboxEvent(entity.WebhookMainChanged, blizzardID, 0, change)
+		if err != nil {
+			return errors.NewAppError("failed build main changed event", err)
+		}
+		if err := repo.SaveOutboxEvents(ctx, []entity.OutboxEvent{event}); err != nil {
+			return err
+		}
+
+		return saveDomainEvent(ctx, repo, entity.EventMainChanged, entity.MainChangedVersion, entity.MainChangedV1{
+			BlizzardID:          blizzardID,
+			CharacterID:         current.CharacterID,
+			Name:                current.Name,
+			Realm:               current.Realm,
+			PreviousCharacterID: change.OldCharacterID,
+		})
+	}); err != nil {
 		return err
 	}
-	uc.log.WithFields(logrus.Fields{
+	uc.logFor(ctx).WithFields(logrus.Fields{
 		"character": charName,
 	}).Info("Set main character successfully")
 
 	return nil
 }
 
+func (uc *profileUsecase) UpdatePreference(ctx context.Context, blizzardID, realm, charName string, pref entity.Preference) error {
+	if blizzardID == "" || charName == "" {
+		uc.logFor(ctx).Error("blizzardID or charcater name is empty")
+		return errors.NewAppError("blizzardID or charcater name is empty", nil)
+	}
+
+	if len([]rune(pref.Note)) > maxNoteLength {
+		uc.logFor(ctx).WithField("character", charName).Warn("character note is too long")
+		return errors.NewAppError(fmt.Sprintf("note is longer than %d characters", maxNoteLength), nil)
+	}
+
+	if err := uc.dbAd.SavePreference(ctx, blizzardID, uc.characterRealmSlug(realm), charName, pref); err != nil {
+		return err
+	}
+	uc.logFor(ctx).WithFields(logrus.Fields{
+		"character": charName,
+	}).Info("Update character preference successfully")
+
+	return nil
+}
+
+// characterRealmSlug resolves an optional realm name or slug; an empty realm
+// leaves character lookups unscoped.
+func (uc *profileUsecase) characterRealmSlug(realm string) string {
+	if realm == "" {
+		return ""
+	}
+	return uc.realms.Slug(realm)
+}
+
 func (uc *profileUsecase) GetMainCharacterByBlizzardID(ctx context.Context, blizzardID string) (*entity.Character, error) {
 	if blizzardID == "" {
-		uc.log.Error("blizzardID is empty")
+		uc.logFor(ctx).Error("blizzardID is empty")
 		return nil, errors.NewAppError("blizzardID is empty", nil)
 	}
 
 	char, err := uc.dbAd.GetMainCharacterByBlizzardID(ctx, blizzardID)
 	if err != nil {
-		uc.log.WithError(err).Errorf("character not found")
+		uc.logFor(ctx).WithError(err).Errorf("character not found")
 		return nil, err
 	}
 
-	uc.log.WithFields(logrus.Fields{
+	uc.logFor(ctx).WithFields(logrus.Fields{
 		"blizzard_id": blizzardID,
 	}).Info("Get main character succeeded")
 
 	return char, nil
 }
+
+// GetMains resolves the main of every requested account in one query. Results
+// keep the request order, Blizzard IDs first; queries matching no account are
+// reported in Missing.
+func (uc *profileUsecase) GetMains(ctx context.Context, blizzardIDs, battletags []string) (*entity.MainLookupResult, error) {
+	blizzardIDs = uniqueTrimmed(blizzardIDs)
+	battletags = uniqueTrimmed(battletags)
+
+	total := len(blizzardIDs) + len(battletags)
+	if total == 0 {
+		uc.logFor(ctx).Error("no blizzard ids or battletags")
+		return nil, errors.NewAppError("no blizzard ids or battletags", nil)
+	}
+	if total > maxMainLookup {
+		uc.logFor(ctx).WithField("count", total).Warn("too many accounts requested")
+		return nil, errors.NewAppError(fmt.Sprintf("more than %d accounts requested", maxMainLookup), nil)
+	}
+
+	lowered := make([]string, len(battletags))
+	for i, tag := range battletags {
+		lowered[i] = strings.ToLower(tag)
+	}
+
+	chars, err := uc.dbAd.GetMains(ctx, blizzardIDs, lowered)
+	if err != nil {
+		uc.logFor(ctx).WithError(err).Error("failed get mains")
+		return nil, err
+	}
+
+	byID := make(map[string]entity.Character, len(chars))
+	byTag := make(map[string]entity.Character, len(chars))
+	for _, c := range chars {
+		byID[c.BlizzardID] = c
+		byTag[strings.ToLower(c.Battletag)] = c
+	}
+
+	result := &entity.MainLookupResult{
+		Mains:   make([]entity.AccountMain, 0, total),
+		Missing: make([]string, 0),
+	}
+	add := func(query string, c entity.Character, ok bool) {
+		if !ok {
+			result.Missing = append(result.Missing, query)
+			return
+		}
+		source := entity.MainSourceBestScore
+		if c.IsMain {
+			source = entity.MainSourceMain
+		}
+		result.Mains = append(result.Mains, entity.AccountMain{Query: query, Source: source, Character: c})
+	}
+	for _, id := range blizzardIDs {
+		c, ok := byID[id]
+		add(id, c, ok)
+	}
+	for i, tag := range battletags {
+		c, ok := byTag[lowered[i]]
+		add(tag, c, ok)
+	}
+
+	uc.logFor(ctx).WithFields(logrus.Fields{
+		"requested": total,
+		"found":     len(result.Mains),
+		"missing":   len(result.Missing),
+	}).Info("Get mains succeeded")
+
+	return result, nil
+}
+
+func uniqueTrimmed(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		key := strings.ToLower(v)
+		if v == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+func (uc *profileUsecase) GetAccountSummary(ctx context.Context, blizzardID string) (*entity.AccountSummary, error) {
+	if blizzardID == "" {
+		uc.logFor(ctx).Error("blizzardID is empty")
+		return nil, errors.NewAppError("blizzardID is empty", nil)
+	}
+
+	summary, err := uc.dbAd.GetAccountSummary(ctx, blizzardID, uc.cfg.Load().Profile.MaxLevel)
+	if err != nil {
+		uc.logFor(ctx).WithError(err).WithField("blizzard_id", blizzardID).Error("failed get account summary")
+		return nil, err
+	}
+
+	summary.AltsByClass = make(map[string][]entity.Character)
+	summary.AltsByRole = make(map[string][]entity.Character)
+	for _, alt := range summary.Alts {
+		summary.AltsByClass[alt.Class] = append(summary.AltsByClass[alt.Class], alt)
+		role := entity.SpecRole(alt.Spec)
+		summary.AltsByRole[role] = append(summary.AltsByRole[role], alt)
+	}
+
+	uc.logFor(ctx).WithFields(logrus.Fields{
+		"blizzard_id": blizzardID,
+	}).Info("Get account summary succeeded")
+
+	return summary, nil
+}
+
+func (uc *profileUsecase) GetGuildLeaderboard(ctx context.Context, filter entity.LeaderboardFilter) (*entity.LeaderboardPage, error) {
+	if filter.GuildID <= 0 {
+		uc.logFor(ctx).Error("guild id is empty")
+		return nil, errors.NewAppError("guild id is empty", nil)
+	}
+
+	switch filter.SortBy {
+	case entity.LeaderboardByMythicScore, entity.LeaderboardByIlvl:
+	default:
+		uc.logFor(ctx).WithField("sort_by", filter.SortBy).Warn("unsupported leaderboard sort")
+		return nil, errors.NewAppError("unsupported leaderboard sort", nil)
+	}
+
+	switch filter.Role {
+	case "", entity.RoleTank, entity.RoleHealer, entity.RoleDamage:
+	default:
+		uc.logFor(ctx).WithField("role", filter.Role).Warn("unsupported leaderboard role")
+		return nil, errors.NewAppError("unsupported leaderboard role", nil)
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLeaderboardLimit
+	}
+	if filter.Limit > maxLeaderboardLimit {
+		filter.Limit = maxLeaderboardLimit
+	}
+
+	page, err := uc.dbAd.GetGuildLeaderboard(ctx, filter)
+	if err != nil {
+		uc.logFor(ctx).WithError(err).WithField("guild_id", filter.GuildID).Error("failed get guild leaderboard")
+		return nil, err
+	}
+
+	uc.logFor(ctx).WithFields(logrus.Fields{
+		"guild_id": filter.GuildID,
+		"sort_by":  filter.SortBy,
+	}).Info("Get guild leaderboard succeeded")
+
+	return page, nil
+}
+
+func (uc *profileUsecase) Search(ctx context.Context, filter entity.SearchFilter) (*entity.SearchResult, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if len([]rune(filter.Query)) < minSearchQueryLength {
+		uc.logFor(ctx).WithField("query", filter.Query).Warn("search query is too short")
+		return nil, errors.NewAppError(fmt.Sprintf("search query is shorter than %d characters", minSearchQueryLength), nil)
+	}
+
+	if filter.Realm != "" {
+		filter.Realm = uc.realms.Slug(filter.Realm)
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSearchLimit
+	}
+	if filter.Limit > maxSearchLimit {
+		filter.Limit = maxSearchLimit
+	}
+
+	result, err := uc.dbAd.Search(ctx, filter)
+	if err != nil {
+		uc.logFor(ctx).WithError(err).WithField("query", filter.Query).Error("failed search")
+		return nil, err
+	}
+
+	uc.logFor(ctx).WithFields(logrus.Fields{
+		"query":      filter.Query,
+		"characters": len(result.Characters),
+		"guilds":     len(result.Guilds),
+	}).Info("Search succeeded")
+
+	return result, nil
+}
+
+func (uc *profileUsecase) logFor(ctx context.Context) *logrus.Entry {
+	return logger.FromContext(ctx, uc.log)
+}
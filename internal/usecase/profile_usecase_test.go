@@ -0,0 +1,629 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"profile-service/internal/adapter/database"
+	"profile-service/internal/dbtest"
+	"profile-service/internal/entity"
+	"profile-service/pkg/config"
+	"profile-service/pkg/slug"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+const testBlizzardID = "100"
+
+func newTestUsecase(t *testing.T) (*profileUsecase, *pgxpool.Pool) {
+	t.Helper()
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	pool := dbtest.Pool(t)
+	repo := database.NewPostgresRepository(pool, nil, log)
+	uc := NewProfileUsecase(repo, nil, slug.NewRealmIndex(), &config.Config{}, log)
+	return uc, pool
+}
+
+func testCharacter(blizzardID string, id int, name string) entity.Character {
+	return entity.Character{
+		CharacterID: id,
+		BlizzardID:  blizzardID,
+		Battletag:   "Tester#1234",
+		Name:        name,
+		Realm:       "Silvermoon",
+		RealmSlug:   "silvermoon",
+		Race:        "Human",
+		Faction:     "Alliance",
+		Class:       "Mage",
+		Spec:        "Frost",
+		Lvl:         80,
+		Ilvl:        620,
+		MythicScore: 2450.5,
+	}
+}
+
+func TestSetMainSurvivesRefresh(t *testing.T) {
+	uc, pool := newTestUsecase(t)
+	ctx := context.Background()
+
+	jaina := testCharacter(testBlizzardID, 1, "Jaina")
+	thrall := testCharacter(testBlizzardID, 2, "Thrall")
+	if err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{Characters: []entity.Character{jaina, thrall}}); err != nil {
+		t.Fatalf("initial sync: %v", err)
+	}
+
+	if err := uc.SetMain(ctx, testBlizzardID, "", "thrall"); err != nil {
+		t.Fatalf("SetMain: %v", err)
+	}
+
+	// Blizzard never knows about the main, so every fetched character has
+	// IsMain false.
+	thrall.Ilvl = 625
+	if err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{Characters: []entity.Character{jaina, thrall}}); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	var isMain bool
+	if err := pool.QueryRow(ctx, "SELECT is_main FROM profile_preference WHERE character_id = $1", thrall.CharacterID).Scan(&isMain); err != nil {
+		t.Fatalf("read preference: %v", err)
+	}
+	if !isMain {
+		t.Error("profile_preference.is_main was reset by the refresh")
+	}
+
+	main, err := uc.GetMainCharacterByBlizzardID(ctx, testBlizzardID)
+	if err != nil {
+		t.Fatalf("GetMainCharacterByBlizzardID: %v", err)
+	}
+	if main.CharacterID != thrall.CharacterID || main.Ilvl != 625 {
+		t.Errorf("main = %s ilvl %d, want the refreshed Thrall", main.Name, main.Ilvl)
+	}
+}
+
+func TestSetMainRejectsAnotherAccountsCharacter(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	if err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{Characters: []entity.Character{testCharacter(testBlizzardID, 1, "Jaina")}}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if err := uc.saveSync(ctx, "200", &entity.SyncBatch{Characters: []entity.Character{testCharacter("200", 2, "Thrall")}}); err != nil {
+		t.Fatalf("sync other account: %v", err)
+	}
+
+	err := uc.SetMain(ctx, testBlizzardID, "", "Thrall")
+	if !errors.Is(err, database.ErrCharacterNotFound) {
+		t.Fatalf("SetMain error = %v, want ErrCharacterNotFound", err)
+	}
+
+	if _, err := uc.GetMainCharacterByBlizzardID(ctx, testBlizzardID); !errors.Is(err, database.ErrCharacterNotFound) {
+		t.Errorf("account has a main after a rejected SetMain: %v", err)
+	}
+}
+
+func TestSetMainMatchesExactNameOnRealm(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	jaina := testCharacter(testBlizzardID, 1, "Jaina")
+	jainaAlt := testCharacter(testBlizzardID, 2, "Jaina")
+	jainaAlt.Realm, jainaAlt.RealmSlug = "Argent Dawn", "argent-dawn"
+	thrall := testCharacter(testBlizzardID, 3, "Thrall")
+	if err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{Characters: []entity.Character{jaina, jainaAlt, thrall}}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		realm   string
+		char    string
+		wantErr error
+		wantID  int
+	}{
+		{name: "ambiguous without realm", char: "jaina", wantErr: database.ErrCharacterAmbiguous},
+		{name: "realm name", realm: "Argent Dawn", char: "JAINA", wantID: jainaAlt.CharacterID},
+		{name: "realm slug", realm: "silvermoon", char: "Jaina", wantID: jaina.CharacterID},
+		{name: "unique without realm", char: "thrall", wantID: thrall.CharacterID},
+		{name: "wrong realm", realm: "argent-dawn", char: "Thrall", wantErr: database.ErrCharacterNotFound},
+		{name: "percent is not a wildcard", char: "Thr%", wantErr: database.ErrCharacterNotFound},
+		{name: "underscore is not a wildcard", char: "Thr_ll", wantErr: database.ErrCharacterNotFound},
+		{name: "prefix is not a match", char: "Thra", wantErr: database.ErrCharacterNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := uc.SetMain(ctx, testBlizzardID, tt.realm, tt.char)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SetMain error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetMain: %v", err)
+			}
+
+			main, err := uc.GetMainCharacterByBlizzardID(ctx, testBlizzardID)
+			if err != nil {
+				t.Fatalf("GetMainCharacterByBlizzardID: %v", err)
+			}
+			if main.CharacterID != tt.wantID {
+				t.Errorf("main = %d, want %d", main.CharacterID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestUpdatePreferenceMatchesExactNameOnRealm(t *testing.T) {
+	uc, pool := newTestUsecase(t)
+	ctx := context.Background()
+
+	jaina := testCharacter(testBlizzardID, 1, "Jaina")
+	jainaAlt := testCharacter(testBlizzardID, 2, "Jaina")
+	jainaAlt.Realm, jainaAlt.RealmSlug = "Argent Dawn", "argent-dawn"
+	if err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{Characters: []entity.Character{jaina, jainaAlt}}); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+
+	pref := entity.Preference{Hidden: true, Note: "bank"}
+	if err := uc.UpdatePreference(ctx, testBlizzardID, "", "jaina", pref); !errors.Is(err, database.ErrCharacterAmbiguous) {
+		t.Fatalf("UpdatePreference without realm error = %v, want ErrCharacterAmbiguous", err)
+	}
+	if err := uc.UpdatePreference(ctx, testBlizzardID, "Argent Dawn", "jaina", pref); err != nil {
+		t.Fatalf("UpdatePreference: %v", err)
+	}
+
+	var hidden []int
+	rows, err := pool.Query(ctx, "SELECT character_id FROM profile_preference WHERE hidden")
+	if err != nil {
+		t.Fatalf("read preferences: %v", err)
+	}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			t.Fatalf("scan preference: %v", err)
+		}
+		hidden = append(hidden, id)
+	}
+	if rows.Err() != nil {
+		t.Fatalf("read preferences: %v", rows.Err())
+	}
+	if len(hidden) != 1 || hidden[0] != jainaAlt.CharacterID {
+		t.Errorf("hidden characters = %v, want only %d", hidden, jainaAlt.CharacterID)
+	}
+}
+
+func TestRefreshRecordsGuildRosterChanges(t *testing.T) {
+	uc, pool := newTestUsecase(t)
+	ctx := context.Background()
+
+	guild := entity.Guild{GuildID: 10, Name: "Test Guild", NameSlug: "test-guild", Realm: "Silvermoon", RealmSlug: "silvermoon", Faction: "Alliance"}
+	inGuild := func(characterID int) entity.Guild {
+		g := guild
+		g.CharacterID = characterID
+		return g
+	}
+
+	jaina := testCharacter(testBlizzardID, 1, "Jaina")
+	thrall := testCharacter(testBlizzardID, 2, "Thrall")
+	if err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{
+		Characters: []entity.Character{jaina, thrall},
+		Guilds:     []entity.Guild{inGuild(jaina.CharacterID)},
+	}); err != nil {
+		t.Fatalf("initial sync: %v", err)
+	}
+	if n := countGuildEvents(t, pool); n != 0 {
+		t.Fatalf("got %d guild events before the guild has a roster, want 0", n)
+	}
+
+	if err := uc.dbAd.ReplaceGuildMembers(ctx, guild.GuildID, []entity.GuildMember{
+		{CharacterID: jaina.CharacterID, Name: jaina.Name, RealmSlug: jaina.RealmSlug, Rank: 2},
+		{CharacterID: 50, Name: "Anduin", RealmSlug: "silvermoon", Rank: 5},
+	}); err != nil {
+		t.Fatalf("store baseline roster: %v", err)
+	}
+
+	// Thrall joins and Jaina leaves; only a refresh of the account sees it.
+	if err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{
+		Characters: []entity.Character{jaina, thrall},
+		Guilds:     []entity.Guild{inGuild(thrall.CharacterID)},
+	}); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	rows, err := pool.Query(ctx, "SELECT character_id, type FROM guild_event WHERE guild_id = $1 ORDER BY character_id", guild.GuildID)
+	if err != nil {
+		t.Fatalf("read guild events: %v", err)
+	}
+	got := make(map[int]string)
+	for rows.Next() {
+		var (
+			id        int
+			eventType string
+		)
+		if err := rows.Scan(&id, &eventType); err != nil {
+			t.Fatalf("scan guild event: %v", err)
+		}
+		got[id] = eventType
+	}
+	if rows.Err() != nil {
+		t.Fatalf("read guild events: %v", rows.Err())
+	}
+	if len(got) != 2 || got[jaina.CharacterID] != entity.GuildEventLeave || got[thrall.CharacterID] != entity.GuildEventJoin {
+		t.Errorf("guild events = %v, want Jaina leaving and Thrall joining", got)
+	}
+
+	var webhooks int
+	if err := pool.QueryRow(ctx, "SELECT count(*) FROM webhook_outbox WHERE guild_id = $1", guild.GuildID).Scan(&webhooks); err != nil {
+		t.Fatalf("count guild webhooks: %v", err)
+	}
+	if webhooks != 2 {
+		t.Errorf("got %d guild webhooks, want 2", webhooks)
+	}
+
+	members, err := uc.dbAd.GetGuildMembers(ctx, guild.GuildID)
+	if err != nil {
+		t.Fatalf("GetGuildMembers: %v", err)
+	}
+	if len(members) != 2 || members[0].CharacterID != thrall.CharacterID || members[1].CharacterID != 50 {
+		t.Errorf("roster = %+v, want Thrall and Anduin", members)
+	}
+}
+
+func TestRefreshRemovesLeftGuildMembership(t *testing.T) {
+	uc, pool := newTestUsecase(t)
+	ctx := context.Background()
+
+	jaina := testCharacter(testBlizzardID, 1, "Jaina")
+	guild := entity.Guild{CharacterID: jaina.CharacterID, GuildID: 10, Name: "Test Guild", NameSlug: "test-guild", Realm: "Silvermoon", RealmSlug: "silvermoon", Faction: "Alliance"}
+	if err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{
+		Characters: []entity.Character{jaina},
+		Guilds:     []entity.Guild{guild},
+	}); err != nil {
+		t.Fatalf("initial sync: %v", err)
+	}
+	if err := uc.dbAd.ReplaceGuildMembers(ctx, guild.GuildID, []entity.GuildMember{
+		{CharacterID: jaina.CharacterID, Name: jaina.Name, RealmSlug: jaina.RealmSlug, Rank: 2},
+	}); err != nil {
+		t.Fatalf("store baseline roster: %v", err)
+	}
+
+	// Jaina left the guild and is now in none.
+	if err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{
+		Characters: []entity.Character{jaina},
+	}); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	var eventType string
+	if err := pool.QueryRow(ctx, "SELECT type FROM guild_event WHERE guild_id = $1 AND character_id = $2", guild.GuildID, jaina.CharacterID).Scan(&eventType); err != nil {
+		t.Fatalf("read leave event: %v", err)
+	}
+	if eventType != entity.GuildEventLeave {
+		t.Errorf("guild event = %q, want %q", eventType, entity.GuildEventLeave)
+	}
+
+	guilds, err := uc.dbAd.GetCharacterGuilds(ctx, []int{jaina.CharacterID})
+	if err != nil {
+		t.Fatalf("GetCharacterGuilds: %v", err)
+	}
+	if len(guilds) != 0 {
+		t.Errorf("guild rows = %+v, want none after leaving", guilds)
+	}
+
+	member, err := uc.dbAd.IsGuildMember(ctx, testBlizzardID, guild.GuildID)
+	if err != nil {
+		t.Fatalf("IsGuildMember: %v", err)
+	}
+	if member {
+		t.Error("account is still a guild member after its only character left")
+	}
+}
+
+func countGuildEvents(t *testing.T, pool *pgxpool.Pool) int {
+	t.Helper()
+
+	var n int
+	if err := pool.QueryRow(context.Background(), "SELECT count(*) FROM guild_event").Scan(&n); err != nil {
+		t.Fatalf("count guild events: %v", err)
+	}
+	return n
+}
+
+// mainsRepo answers GetMains from a fixed set of accounts and records the
+// arguments it was called with.
+type mainsRepo struct {
+	database.PostgresRepository
+	chars       []entity.Character
+	calls       int
+	blizzardIDs []string
+	battletags  []string
+}
+
+func (r *mainsRepo) GetMains(_ context.Context, blizzardIDs, battletags []string) ([]entity.Character, error) {
+	r.calls++
+	r.blizzardIDs, r.battletags = blizzardIDs, battletags
+
+	var out []entity.Character
+	for _, c := range r.chars {
+		if slices.Contains(blizzardIDs, c.BlizzardID) || slices.Contains(battletags, strings.ToLower(c.Battletag)) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// newStubUsecase builds a usecase over a stubbed repository, for logic that
+// needs no database.
+func newStubUsecase(repo database.PostgresRepository) *profileUsecase {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return NewProfileUsecase(repo, nil, slug.NewRealmIndex(), &config.Config{}, log)
+}
+
+func TestGetMains(t *testing.T) {
+	jaina := testCharacter(testBlizzardID, 1, "Jaina")
+	jaina.IsMain = true
+	anduin := testCharacter("200", 2, "Anduin")
+	anduin.Battletag = "Other#5678"
+
+	repo := &mainsRepo{chars: []entity.Character{jaina, anduin}}
+	uc := newStubUsecase(repo)
+
+	result, err := uc.GetMains(context.Background(),
+		[]string{" 100 ", "999", "100"},
+		[]string{"OTHER#5678", "other#5678", "Nobody#0000", " "},
+	)
+	if err != nil {
+		t.Fatalf("GetMains: %v", err)
+	}
+
+	if !slices.Equal(repo.blizzardIDs, []string{"100", "999"}) {
+		t.Errorf("repo got blizzard ids %q, want trimmed and deduplicated", repo.blizzardIDs)
+	}
+	if !slices.Equal(repo.battletags, []string{"other#5678", "nobody#0000"}) {
+		t.Errorf("repo got battletags %q, want lowercased and deduplicated", repo.battletags)
+	}
+
+	want := []entity.AccountMain{
+		{Query: "100", Source: entity.MainSourceMain},
+		{Query: "OTHER#5678", Source: entity.MainSourceBestScore},
+	}
+	if len(result.Mains) != len(want) {
+		t.Fatalf("got %d mains, want %d: %+v", len(result.Mains), len(want), result.Mains)
+	}
+	for i, w := range want {
+		if m := result.Mains[i]; m.Query != w.Query || m.Source != w.Source {
+			t.Errorf("main %d = %s (%s), want %s (%s)", i, m.Query, m.Source, w.Query, w.Source)
+		}
+	}
+	if result.Mains[1].Character.Name != "Anduin" {
+		t.Errorf("battletag resolved to %s, want Anduin", result.Mains[1].Character.Name)
+	}
+	if !slices.Equal(result.Missing, []string{"999", "Nobody#0000"}) {
+		t.Errorf("missing = %q, want [999 Nobody#0000]", result.Missing)
+	}
+}
+
+func TestGetMainsBatchLimit(t *testing.T) {
+	ids := func(n int) []string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = strconv.Itoa(i)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name       string
+		ids        []string
+		battletags []string
+		wantErr    bool
+	}{
+		{name: "nothing requested", wantErr: true},
+		{name: "only blanks", ids: []string{" "}, battletags: []string{""}, wantErr: true},
+		{name: "at the limit", ids: ids(maxMainLookup)},
+		{name: "duplicates do not count", ids: append(ids(maxMainLookup), "0", "1")},
+		{name: "over the limit", ids: ids(maxMainLookup), battletags: []string{"Tester#1234"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mainsRepo{}
+			_, err := newStubUsecase(repo).GetMains(context.Background(), tt.ids, tt.battletags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantErr && repo.calls != 0 {
+				t.Error("rejected batch reached the repository")
+			}
+		})
+	}
+}
+
+// summaryRepo returns a fixed account summary and records the level cap it
+// was asked for.
+type summaryRepo struct {
+	database.PostgresRepository
+	summary  entity.AccountSummary
+	maxLevel int
+}
+
+func (r *summaryRepo) GetAccountSummary(_ context.Context, _ string, maxLevel int) (*entity.AccountSummary, error) {
+	r.maxLevel = maxLevel
+	summary := r.summary
+	return &summary, nil
+}
+
+func TestGetAccountSummaryGroupsAlts(t *testing.T) {
+	alt := func(id int, name, class, spec string) entity.Character {
+		c := testCharacter(testBlizzardID, id, name)
+		c.Class, c.Spec = class, spec
+		return c
+	}
+
+	repo := &summaryRepo{summary: entity.AccountSummary{
+		BlizzardID: testBlizzardID,
+		Alts: []entity.Character{
+			alt(2, "Thrall", "Shaman", "Restoration"),
+			alt(3, "Garrosh", "Warrior", "Protection"),
+			alt(4, "Varian", "Warrior", "Fury"),
+			alt(5, "Rexxar", "Hunter", ""),
+		},
+	}}
+	uc := newStubUsecase(repo)
+	uc.cfg.Load().Profile.MaxLevel = 80
+
+	summary, err := uc.GetAccountSummary(context.Background(), testBlizzardID)
+	if err != nil {
+		t.Fatalf("GetAccountSummary: %v", err)
+	}
+	if repo.maxLevel != 80 {
+		t.Errorf("repo asked for level cap %d, want the configured 80", repo.maxLevel)
+	}
+
+	names := func(chars []entity.Character) []string {
+		out := make([]string, len(chars))
+		for i, c := range chars {
+			out[i] = c.Name
+		}
+		return out
+	}
+
+	byClass := map[string][]string{
+		"Shaman":  {"Thrall"},
+		"Warrior": {"Garrosh", "Varian"},
+		"Hunter":  {"Rexxar"},
+	}
+	if len(summary.AltsByClass) != len(byClass) {
+		t.Errorf("got %d classes, want %d", len(summary.AltsByClass), len(byClass))
+	}
+	for class, want := range byClass {
+		if got := names(summary.AltsByClass[class]); !slices.Equal(got, want) {
+			t.Errorf("%s alts = %v, want %v", class, got, want)
+		}
+	}
+
+	byRole := map[string][]string{
+		entity.RoleHealer:  {"Thrall"},
+		entity.RoleTank:    {"Garrosh"},
+		entity.RoleDamage:  {"Varian"},
+		entity.RoleUnknown: {"Rexxar"},
+	}
+	if len(summary.AltsByRole) != len(byRole) {
+		t.Errorf("got %d roles, want %d", len(summary.AltsByRole), len(byRole))
+	}
+	for role, want := range byRole {
+		if got := names(summary.AltsByRole[role]); !slices.Equal(got, want) {
+			t.Errorf("%s alts = %v, want %v", role, got, want)
+		}
+	}
+}
+
+// leaderboardRepo records the filter the usecase passes down.
+type leaderboardRepo struct {
+	database.PostgresRepository
+	filter *entity.LeaderboardFilter
+}
+
+func (r *leaderboardRepo) GetGuildLeaderboard(_ context.Context, filter entity.LeaderboardFilter) (*entity.LeaderboardPage, error) {
+	r.filter = &filter
+	return &entity.LeaderboardPage{GuildID: filter.GuildID, SortBy: filter.SortBy}, nil
+}
+
+func TestGetGuildLeaderboardFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    entity.LeaderboardFilter
+		wantErr   bool
+		wantLimit int
+	}{
+		{name: "default limit", filter: entity.LeaderboardFilter{GuildID: 10, SortBy: entity.LeaderboardByIlvl}, wantLimit: defaultLeaderboardLimit},
+		{name: "limit capped", filter: entity.LeaderboardFilter{GuildID: 10, SortBy: entity.LeaderboardByMythicScore, Limit: 500}, wantLimit: maxLeaderboardLimit},
+		{name: "limit kept", filter: entity.LeaderboardFilter{GuildID: 10, SortBy: entity.LeaderboardByMythicScore, Limit: 5, Role: entity.RoleHealer}, wantLimit: 5},
+		{name: "no guild", filter: entity.LeaderboardFilter{SortBy: entity.LeaderboardByIlvl}, wantErr: true},
+		{name: "unknown sort", filter: entity.LeaderboardFilter{GuildID: 10, SortBy: "achievement_points"}, wantErr: true},
+		{name: "unknown role", filter: entity.LeaderboardFilter{GuildID: 10, SortBy: entity.LeaderboardByIlvl, Role: "support"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &leaderboardRepo{}
+			_, err := newStubUsecase(repo).GetGuildLeaderboard(context.Background(), tt.filter)
+			if tt.wantErr {
+				if err == nil || repo.filter != nil {
+					t.Errorf("err = %v, repo called = %v, want a rejected filter", err, repo.filter != nil)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetGuildLeaderboard: %v", err)
+			}
+			if repo.filter.Limit != tt.wantLimit {
+				t.Errorf("limit = %d, want %d", repo.filter.Limit, tt.wantLimit)
+			}
+		})
+	}
+}
+
+// searchRepo records the filter the usecase passes down.
+type searchRepo struct {
+	database.PostgresRepository
+	filter *entity.SearchFilter
+}
+
+func (r *searchRepo) Search(_ context.Context, filter entity.SearchFilter) (*entity.SearchResult, error) {
+	r.filter = &filter
+	return &entity.SearchResult{Query: filter.Query}, nil
+}
+
+func TestSearchFilter(t *testing.T) {
+	realms := slug.NewRealmIndex()
+	realms.Load([]slug.Realm{{ID: 1, Slug: "howling-fjord", Names: map[string]string{"ru_RU": "Ревущий фьорд"}}})
+
+	tests := []struct {
+		name    string
+		filter  entity.SearchFilter
+		wantErr bool
+		want    entity.SearchFilter
+	}{
+		{name: "too short", filter: entity.SearchFilter{Query: " j "}, wantErr: true},
+		{name: "two cyrillic letters", filter: entity.SearchFilter{Query: "Дж"}, want: entity.SearchFilter{Query: "Дж", Limit: defaultSearchLimit}},
+		{name: "limit capped", filter: entity.SearchFilter{Query: "jaina", Limit: 1000}, want: entity.SearchFilter{Query: "jaina", Limit: maxSearchLimit}},
+		{
+			name:   "realm name resolved",
+			filter: entity.SearchFilter{Query: " jaina ", Realm: "Ревущий фьорд", Limit: 5},
+			want:   entity.SearchFilter{Query: "jaina", Realm: "howling-fjord", Limit: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &searchRepo{}
+			uc := newStubUsecase(repo)
+			uc.realms = realms
+
+			_, err := uc.Search(context.Background(), tt.filter)
+			if tt.wantErr {
+				if err == nil || repo.filter != nil {
+					t.Errorf("err = %v, repo called = %v, want a rejected query", err, repo.filter != nil)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if *repo.filter != tt.want {
+				t.Errorf("filter = %+v, want %+v", *repo.filter, tt.want)
+			}
+		})
+	}
+}
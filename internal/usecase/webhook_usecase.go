@@ -0,0 +1,14 @@
+package usecase
+
+import (
+	"context"
+	"profile-service/internal/entity"
+)
+
+type WebhookUsecase interface {
+	CreateSubscription(ctx context.Context, blizzardID string, sub entity.WebhookSubscription) (*entity.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, blizzardID string) ([]entity.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, blizzardID string, id int64) error
+	ListDeliveries(ctx context.Context, blizzardID string, subscriptionID int64, limit int) ([]entity.WebhookDelivery, error)
+	Run(ctx context.Context)
+}
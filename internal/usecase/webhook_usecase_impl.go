@@ -0,0 +1,292 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"profile-service/internal/adapter/database"
+	"profile-service/internal/adapter/webhook"
+	"profile-service/internal/entity"
+	"profile-service/pkg/config"
+	"profile-service/pkg/errors"
+	logger "profile-service/pkg/log"
+	"profile-service/pkg/workerpool"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxWebhookSubscriptions = 10
+	defaultDeliveryLimit    = 50
+	maxDeliveryLimit        = 200
+	webhookPruneInterval    = time.Hour
+	maxWebhookErrorLength   = 500
+)
+
+type webhookUsecase struct {
+	dbAd   database.PostgresRepository
+	client webhook.WebhookClient
+	pool   *workerpool.Pool
+	cfg    atomic.Pointer[config.Config]
+	log    *logrus.Logger
+}
+
+func NewWebhookUsecase(
+	dbAd database.PostgresRepository,
+	client webhook.WebhookClient,
+	cfg *config.Config,
+	log *logrus.Logger,
+) *webhookUsecase {
+	uc := &webhookUsecase{
+		dbAd:   dbAd,
+		client: client,
+		pool:   workerpool.New(cfg.Webhooks.Workers, cfg.Webhooks.Timeout),
+		log:    log,
+	}
+	uc.cfg.Store(cfg)
+	return uc
+}
+
+// ApplyConfig picks up reloaded retry and worker settings on the next tick.
+func (uc *webhookUsecase) ApplyConfig(cfg *config.Config) {
+	uc.cfg.Store(cfg)
+	uc.pool.Resize(cfg.Webhooks.Workers, cfg.Webhooks.Timeout)
+}
+
+func (uc *webhookUsecase) CreateSubscription(ctx context.Context, blizzardID string, sub entity.WebhookSubscription) (*entity.WebhookSubscription, error) {
+	if blizzardID == "" {
+		uc.logFor(ctx).Error("blizzardID is empty")
+		return nil, errors.NewAppError("blizzardID is empty", nil)
+	}
+
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, errors.NewAppError("invalid webhook subscription: url must be an absolute http(s) URL", nil)
+	}
+
+	types := make([]string, 0, len(sub.EventTypes))
+	seen := make(map[string]bool, len(sub.EventTypes))
+	guildEvents := false
+	for _, t := range sub.EventTypes {
+		if !entity.IsWebhookEventType(t) {
+			return nil, errors.NewAppError(fmt.Sprintf("invalid webhook subscription: unknown event type %q", t), nil)
+		}
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		types = append(types, t)
+		guildEvents = guildEvents || strings.HasPrefix(t, "guild.")
+	}
+	if len(types) == 0 {
+		return nil, errors.NewAppError("invalid webhook subscription: event_types is empty", nil)
+	}
+	if guildEvents && sub.GuildID == nil {
+		return nil, errors.NewAppError("invalid webhook subscription: guild events need guild_id", nil)
+	}
+
+	if sub.GuildID != nil {
+		member, err := uc.dbAd.IsGuildMember(ctx, blizzardID, *sub.GuildID)
+		if err != nil {
+			return nil, err
+		}
+		if !member {
+			uc.logFor(ctx).WithField("guild_id", *sub.GuildID).Warn("webhook subscription for foreign guild")
+			return nil, errors.NewAppError("invalid webhook subscription: no character in this guild", nil)
+		}
+	}
+
+	existing, err := uc.dbAd.GetWebhookSubscriptions(ctx, blizzardID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxWebhookSubscriptions {
+		return nil, errors.NewAppError(fmt.Sprintf("invalid webhook subscription: at most %d subscriptions per account", maxWebhookSubscriptions), nil)
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, errors.NewAppError("failed generate webhook secret", err)
+	}
+
+	created := &entity.WebhookSubscription{
+		BlizzardID: blizzardID,
+		URL:        u.String(),
+		Secret:     secret,
+		EventTypes: types,
+		GuildID:    sub.GuildID,
+		Active:     true,
+	}
+	if err := uc.dbAd.CreateWebhookSubscription(ctx, created); err != nil {
+		return nil, err
+	}
+
+	uc.logFor(ctx).WithFields(logrus.Fields{
+		"subscription_id": created.ID,
+		"event_types":     types,
+	}).Info("Webhook subscription created")
+
+	return created, nil
+}
+
+func (uc *webhookUsecase) ListSubscriptions(ctx context.Context, blizzardID string) ([]entity.WebhookSubscription, error) {
+	if blizzardID == "" {
+		uc.logFor(ctx).Error("blizzardID is empty")
+		return nil, errors.NewAppError("blizzardID is empty", nil)
+	}
+	return uc.dbAd.GetWebhookSubscriptions(ctx, blizzardID)
+}
+
+func (uc *webhookUsecase) DeleteSubscription(ctx context.Context, blizzardID string, id int64) error {
+	if blizzardID == "" {
+		uc.logFor(ctx).Error("blizzardID is empty")
+		return errors.NewAppError("blizzardID is empty", nil)
+	}
+
+	if err := uc.dbAd.DeleteWebhookSubscription(ctx, blizzardID, id); err != nil {
+		return err
+	}
+
+	uc.logFor(ctx).WithField("subscription_id", id).Info("Webhook subscription deleted")
+	return nil
+}
+
+func (uc *webhookUsecase) ListDeliveries(ctx context.Context, blizzardID string, subscriptionID int64, limit int) ([]entity.WebhookDelivery, error) {
+	if blizzardID == "" {
+		uc.logFor(ctx).Error("blizzardID is empty")
+		return nil, errors.NewAppError("blizzardID is empty", nil)
+	}
+
+	if limit <= 0 {
+		limit = defaultDeliveryLimit
+	}
+	if limit > maxDeliveryLimit {
+		limit = maxDeliveryLimit
+	}
+
+	return uc.dbAd.GetWebhookDeliveries(ctx, blizzardID, subscriptionID, limit)
+}
+
+// Run moves outbox events into per-subscription deliveries and sends the due
+// ones until ctx is cancelled. Several instances may run it concurrently.
+func (uc *webhookUsecase) Run(ctx context.Context) {
+	uc.log.Info("Webhook dispatcher started")
+	defer uc.log.Info("Webhook dispatcher stopped")
+
+	lastPrune := time.Time{}
+	for {
+		cfg := uc.cfg.Load()
+
+		if time.Since(lastPrune) >= webhookPruneInterval {
+			if err := uc.dbAd.PruneWebhooks(ctx, time.Now().Add(-cfg.Webhooks.Retention)); err != nil {
+				uc.log.WithError(err).Warn("failed prune webhook tables")
+			}
+			lastPrune = time.Now()
+		}
+
+		uc.tick(ctx, cfg)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(cfg.Webhooks.PollInterval):
+		}
+	}
+}
+
+func (uc *webhookUsecase) tick(ctx context.Context, cfg *config.Config) {
+	queued, err := uc.dbAd.FanOutOutbox(ctx, cfg.Webhooks.BatchSize)
+	if err != nil {
+		uc.log.WithError(err).Warn("failed fan out webhook events")
+	} else if queued > 0 {
+		uc.log.Debugf("Queued %d webhook deliveries", queued)
+	}
+
+	deliveries, err := uc.dbAd.ClaimWebhookDeliveries(ctx, cfg.Webhooks.BatchSize, 2*cfg.Webhooks.Timeout)
+	if err != nil {
+		uc.log.WithError(err).Warn("failed claim webhook deliveries")
+		return
+	}
+
+	workerpool.Map(ctx, uc.pool, deliveries, func(ctx context.Context, d entity.WebhookDelivery) struct{} {
+		uc.deliver(ctx, cfg, d)
+		return struct{}{}
+	})
+}
+
+func (uc *webhookUsecase) deliver(ctx context.Context, cfg *config.Config, d entity.WebhookDelivery) {
+	code, err := uc.client.Deliver(ctx, d)
+
+	d.Attempts++
+	if code != 0 {
+		d.ResponseCode = &code
+	}
+
+	now := time.Now()
+	switch {
+	case err == nil:
+		d.Status = entity.WebhookDeliveryDelivered
+		d.LastError = ""
+		d.DeliveredAt = &now
+	case d.Attempts >= cfg.Webhooks.MaxAttempts:
+		d.Status = entity.WebhookDeliveryFailed
+		d.LastError = truncate(err.Error(), maxWebhookErrorLength)
+	default:
+		d.Status = entity.WebhookDeliveryPending
+		d.LastError = truncate(err.Error(), maxWebhookErrorLength)
+		d.NextAttemptAt = now.Add(webhookBackoff(d.Attempts, cfg.Webhooks.BackoffBase, cfg.Webhooks.BackoffMax))
+	}
+	if d.NextAttemptAt.IsZero() {
+		d.NextAttemptAt = now
+	}
+
+	// The delivery context may already be cancelled by its timeout; the
+	// outcome still has to be recorded.
+	if err := uc.dbAd.FinishWebhookDelivery(context.WithoutCancel(ctx), d); err != nil {
+		uc.log.WithError(err).WithField("delivery_id", d.ID).Warn("failed record webhook delivery")
+		return
+	}
+
+	uc.log.WithFields(logrus.Fields{
+		"delivery_id": d.ID,
+		"event":       d.EventType,
+		"attempt":     d.Attempts,
+		"status":      d.Status,
+	}).Debug("Webhook delivery attempted")
+}
+
+// webhookBackoff doubles the delay after every failed attempt, capped at max.
+func webhookBackoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
+
+func (uc *webhookUsecase) logFor(ctx context.Context) *logrus.Entry {
+	return logger.FromContext(ctx, uc.log)
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"profile-service/internal/adapter/database"
+	"profile-service/internal/adapter/webhook"
+	"profile-service/internal/entity"
+	"profile-service/pkg/config"
+	"profile-service/pkg/metrics"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestWebhookBackoff(t *testing.T) {
+	const (
+		base = time.Second
+		max  = 10 * time.Second
+	)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, max},
+		{50, max},
+	}
+
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts, base, max); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// deliveryRepo records the outcomes deliver writes back.
+type deliveryRepo struct {
+	database.PostgresRepository
+	finished []entity.WebhookDelivery
+}
+
+func (r *deliveryRepo) FinishWebhookDelivery(_ context.Context, d entity.WebhookDelivery) error {
+	r.finished = append(r.finished, d)
+	return nil
+}
+
+func TestDeliverRecordsOutcome(t *testing.T) {
+	// The receiver answers with the status code named by the request path.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		if err != nil {
+			status = http.StatusBadRequest
+		}
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{}
+	cfg.Webhooks.Timeout = 5 * time.Second
+	cfg.Webhooks.AllowPrivate = true
+	cfg.Webhooks.MaxAttempts = 3
+	cfg.Webhooks.BackoffBase = time.Minute
+	cfg.Webhooks.BackoffMax = time.Hour
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	tests := []struct {
+		name       string
+		status     int
+		attempts   int
+		wantStatus string
+		wantRetry  time.Duration
+	}{
+		{name: "success", status: http.StatusOK, wantStatus: entity.WebhookDeliveryDelivered},
+		{name: "retry", status: http.StatusInternalServerError, attempts: 1, wantStatus: entity.WebhookDeliveryPending, wantRetry: 2 * time.Minute},
+		{name: "give up", status: http.StatusBadGateway, attempts: 2, wantStatus: entity.WebhookDeliveryFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &deliveryRepo{}
+			uc := NewWebhookUsecase(repo, webhook.NewWebhookClient(cfg, metrics.New()), cfg, log)
+
+			start := time.Now()
+			uc.deliver(context.Background(), cfg, entity.WebhookDelivery{
+				ID:        1,
+				EventType: entity.WebhookCharacterLevelUp,
+				Attempts:  tt.attempts,
+				URL:       srv.URL + "/" + strconv.Itoa(tt.status),
+				Secret:    "whsec_test",
+			})
+
+			if len(repo.finished) != 1 {
+				t.Fatalf("recorded %d outcomes, want 1", len(repo.finished))
+			}
+			d := repo.finished[0]
+			if d.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", d.Status, tt.wantStatus)
+			}
+			if d.Attempts != tt.attempts+1 {
+				t.Errorf("attempts = %d, want %d", d.Attempts, tt.attempts+1)
+			}
+			if d.ResponseCode == nil || *d.ResponseCode != tt.status {
+				t.Errorf("response code = %v, want %d", d.ResponseCode, tt.status)
+			}
+
+			switch tt.wantStatus {
+			case entity.WebhookDeliveryDelivered:
+				if d.DeliveredAt == nil || d.LastError != "" {
+					t.Errorf("delivered = %v, last error = %q", d.DeliveredAt, d.LastError)
+				}
+			case entity.WebhookDeliveryPending:
+				if next := d.NextAttemptAt.Sub(start); next < tt.wantRetry || next > tt.wantRetry+time.Minute {
+					t.Errorf("next attempt in %s, want %s", next, tt.wantRetry)
+				}
+				fallthrough
+			default:
+				if d.LastError == "" || d.DeliveredAt != nil {
+					t.Errorf("delivered = %v, last error = %q", d.DeliveredAt, d.LastError)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateSubscriptionRejectsLeftGuild(t *testing.T) {
+	uc, _ := newTestUsecase(t)
+	ctx := context.Background()
+
+	cfg := &config.Config{}
+	webhookUc := NewWebhookUsecase(uc.dbAd, nil, cfg, uc.log)
+
+	jaina := testCharacter(testBlizzardID, 1, "Jaina")
+	guildID := 10
+	if err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{
+		Characters: []entity.Character{jaina},
+		Guilds: []entity.Guild{{
+			CharacterID: jaina.CharacterID, GuildID: guildID, Name: "Test Guild", NameSlug: "test-guild",
+			Realm: "Silvermoon", RealmSlug: "silvermoon", Faction: "Alliance",
+		}},
+	}); err != nil {
+		t.Fatalf("initial sync: %v", err)
+	}
+
+	sub := entity.WebhookSubscription{
+		URL:        "https://example.com/hook",
+		EventTypes: []string{entity.WebhookGuildMemberJoined},
+		GuildID:    &guildID,
+	}
+	if _, err := webhookUc.CreateSubscription(ctx, testBlizzardID, sub); err != nil {
+		t.Fatalf("subscribe while in the guild: %v", err)
+	}
+
+	if err := uc.saveSync(ctx, testBlizzardID, &entity.SyncBatch{
+		Characters: []entity.Character{jaina},
+	}); err != nil {
+		t.Fatalf("refresh after leaving: %v", err)
+	}
+
+	if _, err := webhookUc.CreateSubscription(ctx, testBlizzardID, sub); err == nil {
+		t.Error("subscribed to a guild the account's characters left")
+	}
+}
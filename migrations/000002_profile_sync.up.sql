@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS profile_sync (
+    blizzard_id TEXT PRIMARY KEY,
+    characters INTEGER NOT NULL DEFAULT 0,
+    synced_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS character_snapshot (
+    id BIGSERIAL PRIMARY KEY,
+    character_id INTEGER NOT NULL,
+    blizzard_id TEXT NOT NULL,
+    lvl INTEGER NOT NULL,
+    ilvl INTEGER NOT NULL,
+    mythic_score NUMERIC(10,2) NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_character_snapshot_character ON character_snapshot (character_id, recorded_at);
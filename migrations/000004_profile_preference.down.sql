@@ -0,0 +1,10 @@
+ALTER TABLE profile ADD COLUMN IF NOT EXISTS is_main BOOLEAN;
+
+UPDATE profile p
+SET is_main = true
+FROM profile_preference pp
+WHERE pp.character_id = p.character_id AND pp.is_main = true;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_profile_blizzard_id_main ON profile (blizzard_id) WHERE is_main = true;
+
+DROP TABLE IF EXISTS profile_preference;
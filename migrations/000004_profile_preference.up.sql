@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS profile_preference (
+    character_id INTEGER PRIMARY KEY REFERENCES profile(character_id) ON DELETE CASCADE,
+    blizzard_id TEXT NOT NULL,
+    is_main BOOLEAN NOT NULL DEFAULT false,
+    hidden BOOLEAN NOT NULL DEFAULT false,
+    display_order INTEGER,
+    note TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_profile_preference_blizzard_id ON profile_preference (blizzard_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_profile_preference_main ON profile_preference (blizzard_id) WHERE is_main = true;
+
+INSERT INTO profile_preference (character_id, blizzard_id, is_main)
+SELECT character_id, blizzard_id, true
+FROM profile
+WHERE is_main = true
+ON CONFLICT (character_id) DO NOTHING;
+
+DROP INDEX IF EXISTS idx_profile_blizzard_id_main;
+
+ALTER TABLE profile DROP COLUMN IF EXISTS is_main;
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_guild_guild_id_character_id;
+
+DROP INDEX IF EXISTS idx_profile_ilvl;
+
+DROP INDEX IF EXISTS idx_profile_mythic_score;
+
+ALTER TABLE profile
+    ALTER COLUMN mythic_score DROP NOT NULL,
+    ALTER COLUMN mythic_score DROP DEFAULT,
+    ALTER COLUMN ilvl DROP NOT NULL,
+    ALTER COLUMN ilvl DROP DEFAULT;
@@ -0,0 +1,15 @@
+UPDATE profile SET mythic_score = 0 WHERE mythic_score IS NULL;
+
+UPDATE profile SET ilvl = 0 WHERE ilvl IS NULL;
+
+ALTER TABLE profile
+    ALTER COLUMN mythic_score SET DEFAULT 0,
+    ALTER COLUMN mythic_score SET NOT NULL,
+    ALTER COLUMN ilvl SET DEFAULT 0,
+    ALTER COLUMN ilvl SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_profile_mythic_score ON profile (mythic_score DESC, character_id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_profile_ilvl ON profile (ilvl DESC, character_id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_guild_guild_id_character_id ON guild (guild_id, character_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_guild_name_trgm;
+
+DROP INDEX IF EXISTS idx_profile_name_trgm;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_guild_realm_slug_name_slug;
+
+DROP INDEX IF EXISTS idx_profile_realm_slug;
+
+ALTER TABLE profile DROP COLUMN IF EXISTS realm_slug;
+
+DROP TABLE IF EXISTS realm;
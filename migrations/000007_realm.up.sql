@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS realm (
+    id INTEGER PRIMARY KEY,
+    slug VARCHAR(50) NOT NULL UNIQUE,
+    names JSONB NOT NULL DEFAULT '{}'::jsonb,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE profile ADD COLUMN IF NOT EXISTS realm_slug VARCHAR(50);
+
+CREATE INDEX IF NOT EXISTS idx_profile_realm_slug ON profile (realm_slug);
+
+CREATE INDEX IF NOT EXISTS idx_guild_realm_slug_name_slug ON guild (realm_slug, name_slug);
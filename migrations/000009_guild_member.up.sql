@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS guild_member (
+    guild_id INTEGER NOT NULL,
+    character_id INTEGER NOT NULL,
+    name VARCHAR(25) NOT NULL,
+    realm_slug VARCHAR(30) NOT NULL,
+    level INTEGER NOT NULL DEFAULT 0,
+    class_id INTEGER NOT NULL DEFAULT 0,
+    rank INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (guild_id, character_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_guild_member_character_id ON guild_member (character_id);
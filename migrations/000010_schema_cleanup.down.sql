@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_guild_character_id ON guild (character_id);
+
+ALTER TABLE guild ADD CONSTRAINT unique_character_guild UNIQUE (character_id);
@@ -0,0 +1,3 @@
+ALTER TABLE guild DROP CONSTRAINT IF EXISTS unique_character_guild;
+
+DROP INDEX IF EXISTS idx_guild_character_id;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS guild_event (
+    id BIGSERIAL PRIMARY KEY,
+    guild_id INTEGER NOT NULL,
+    character_id INTEGER NOT NULL,
+    name VARCHAR(25) NOT NULL,
+    realm_slug VARCHAR(30) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    old_rank INTEGER,
+    new_rank INTEGER,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_guild_event_guild_id_occurred_at ON guild_event (guild_id, occurred_at DESC, id DESC);
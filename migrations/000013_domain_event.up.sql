@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS domain_event (
+    id TEXT PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    version INTEGER NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    data JSONB NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_domain_event_occurred_at ON domain_event (occurred_at);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_profile_battletag_lower ON profile (lower(battletag));
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_domain_event_unpublished;
+
+ALTER TABLE domain_event DROP COLUMN IF EXISTS published_at;
@@ -0,0 +1,6 @@
+ALTER TABLE domain_event ADD COLUMN IF NOT EXISTS published_at TIMESTAMPTZ;
+
+-- Events stored before the relay existed were notified when they were written.
+UPDATE domain_event SET published_at = occurred_at WHERE published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_domain_event_unpublished ON domain_event (occurred_at, id) WHERE published_at IS NULL;
@@ -0,0 +1,64 @@
+// Package migrations embeds the SQL schema migrations into the binary.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// New returns a migrator applying the embedded migrations to db.
+func New(db *sql.DB) (*migrate.Migrate, error) {
+	src, err := iofs.New(FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("open embedded migrations: %w", err)
+	}
+
+	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("create migrate driver: %w", err)
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		return nil, fmt.Errorf("create migrate instance: %w", err)
+	}
+
+	return m, nil
+}
+
+// Versions lists the embedded migration versions in ascending order.
+func Versions() ([]uint, error) {
+	src, err := iofs.New(FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("open embedded migrations: %w", err)
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return nil, fmt.Errorf("read embedded migrations: %w", err)
+	}
+
+	versions := []uint{version}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return versions, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read embedded migrations: %w", err)
+		}
+		versions = append(versions, next)
+		version = next
+	}
+}
@@ -0,0 +1,37 @@
+package migrations_test
+
+import (
+	"fmt"
+	"io/fs"
+	"profile-service/migrations"
+	"testing"
+)
+
+// TestVersionsMatchEmbeddedFiles guards the embed pattern: every version must
+// be numbered in sequence and ship both an up and a down file.
+func TestVersionsMatchEmbeddedFiles(t *testing.T) {
+	versions, err := migrations.Versions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := fs.Glob(migrations.FS, "*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2*len(versions) {
+		t.Errorf("%d embedded files for %d versions", len(files), len(versions))
+	}
+
+	for i, version := range versions {
+		if version != uint(i+1) {
+			t.Fatalf("version %d at position %d, want %d", version, i, i+1)
+		}
+		for _, direction := range []string{"up", "down"} {
+			matches, _ := fs.Glob(migrations.FS, fmt.Sprintf("%06d_*.%s.sql", version, direction))
+			if len(matches) != 1 {
+				t.Errorf("version %d has %d %s files", version, len(matches), direction)
+			}
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package migrations_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"profile-service/internal/dbtest"
+	"profile-service/migrations"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// TestRoundtrip applies, rolls back and re-applies every migration on a
+// throwaway database; the schema must match after every step.
+//
+// Version 1 is the baseline: it shipped with a down migration that drops a
+// table that never existed and an up migration that cannot run twice, and
+// applied migrations are never edited. Its schema is corrected by later
+// versions instead, so the roundtrip starts from it.
+func TestRoundtrip(t *testing.T) {
+	db, err := sql.Open("pgx", dbtest.Database(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	versions, err := migrations.Versions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	ctx := context.Background()
+
+	baseline := versions[0]
+	if err := m.Migrate(baseline); err != nil {
+		t.Fatalf("up to baseline %d: %v", baseline, err)
+	}
+	base := snapshotSchema(t, ctx, db)
+
+	before := base
+	for _, version := range versions[1:] {
+		if err := m.Steps(1); err != nil {
+			t.Fatalf("up to %d: %v", version, err)
+		}
+		after := snapshotSchema(t, ctx, db)
+
+		if err := m.Steps(-1); err != nil {
+			t.Fatalf("down from %d: %v", version, err)
+		}
+		if diff := diffSchema(before, snapshotSchema(t, ctx, db)); diff != "" {
+			t.Fatalf("down from %d does not restore the previous schema:\n%s", version, diff)
+		}
+
+		if err := m.Steps(1); err != nil {
+			t.Fatalf("re-apply %d: %v", version, err)
+		}
+		if diff := diffSchema(after, snapshotSchema(t, ctx, db)); diff != "" {
+			t.Fatalf("re-applying %d gives a different schema:\n%s", version, diff)
+		}
+
+		before = after
+	}
+
+	if err := m.Migrate(baseline); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("down to baseline %d: %v", baseline, err)
+	}
+	if diff := diffSchema(base, snapshotSchema(t, ctx, db)); diff != "" {
+		t.Fatalf("down to baseline %d leaves objects behind:\n%s", baseline, diff)
+	}
+
+	if err := m.Up(); err != nil {
+		t.Fatalf("final up: %v", err)
+	}
+}
+
+type schemaSnapshot map[string][]string
+
+var schemaKinds = []string{"columns", "indexes", "constraints"}
+
+// snapshotSchema lists columns, indexes and constraints of the public schema,
+// leaving out golang-migrate's own bookkeeping table. Extensions are not
+// compared: down migrations leave them installed, since other objects may
+// depend on them.
+func snapshotSchema(t *testing.T, ctx context.Context, db *sql.DB) schemaSnapshot {
+	t.Helper()
+
+	queries := map[string]string{
+		"columns": `SELECT table_name || '.' || column_name || ' ' || data_type || ' null=' || is_nullable || ' default=' || COALESCE(column_default, '')
+			FROM information_schema.columns
+			WHERE table_schema = 'public' AND table_name <> 'schema_migrations'
+			ORDER BY 1`,
+		"indexes": `SELECT indexdef FROM pg_indexes
+			WHERE schemaname = 'public' AND tablename <> 'schema_migrations'
+			ORDER BY 1`,
+		"constraints": `SELECT conrelid::regclass::text || ' ' || conname || ' ' || pg_get_constraintdef(oid)
+			FROM pg_constraint
+			WHERE connamespace = 'public'::regnamespace AND conrelid::regclass::text <> 'schema_migrations'
+			ORDER BY 1`,
+	}
+
+	snapshot := make(schemaSnapshot, len(queries))
+	for kind, query := range queries {
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			t.Fatalf("snapshot %s: %v", kind, err)
+		}
+
+		items := make([]string, 0)
+		for rows.Next() {
+			var item string
+			if err := rows.Scan(&item); err != nil {
+				rows.Close()
+				t.Fatalf("snapshot %s: %v", kind, err)
+			}
+			items = append(items, item)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			t.Fatalf("snapshot %s: %v", kind, err)
+		}
+		snapshot[kind] = items
+	}
+
+	return snapshot
+}
+
+func diffSchema(want, got schemaSnapshot) string {
+	if reflect.DeepEqual(want, got) {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, kind := range schemaKinds {
+		wantSet := make(map[string]bool, len(want[kind]))
+		for _, item := range want[kind] {
+			wantSet[item] = true
+		}
+		gotSet := make(map[string]bool, len(got[kind]))
+		for _, item := range got[kind] {
+			gotSet[item] = true
+			if !wantSet[item] {
+				fmt.Fprintf(&b, "  + %s: %s\n", kind, item)
+			}
+		}
+		for _, item := range want[kind] {
+			if !gotSet[item] {
+				fmt.Fprintf(&b, "  - %s: %s\n", kind, item)
+			}
+		}
+	}
+	return b.String()
+}
@@ -1,16 +1,20 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"os"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
 	Server struct {
-		Host string `mapstructure:"host"`
-		Port int    `mapstructure:"port"`
+		Host            string        `mapstructure:"host"`
+		Port            int           `mapstructure:"port"`
+		DrainDelay      time.Duration `mapstructure:"drain_delay"`
+		ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
 	} `mapstructure:"server"`
 	DB struct {
 		User    string `mapstructure:"user"`
@@ -19,21 +23,99 @@ type Config struct {
 		Port    int    `mapstructure:"port"`
 		Name    string `mapstructure:"name"`
 		SSLMode string `mapstructure:"sslmode"`
+
+		MaxConns          int32         `mapstructure:"max_conns"`
+		MinConns          int32         `mapstructure:"min_conns"`
+		MaxConnLifetime   time.Duration `mapstructure:"max_conn_lifetime"`
+		MaxConnIdleTime   time.Duration `mapstructure:"max_conn_idle_time"`
+		HealthCheckPeriod time.Duration `mapstructure:"health_check_period"`
+		ConnectTimeout    time.Duration `mapstructure:"connect_timeout"`
+		ConnectAttempts   int           `mapstructure:"connect_attempts"`
+		ConnectRetryDelay time.Duration `mapstructure:"connect_retry_delay"`
+		ReplicaDSN        string        `mapstructure:"replica_dsn"`
+		ReplicaCheck      time.Duration `mapstructure:"replica_check_interval"`
+		Migrations        string        `mapstructure:"migrations"`
 	} `mapstructure:"db"`
+	GRPC struct {
+		Enabled       bool     `mapstructure:"enabled"`
+		Port          int      `mapstructure:"port"`
+		ServiceTokens []string `mapstructure:"service_tokens"`
+	} `mapstructure:"grpc"`
 	Logger struct {
 		Level string `mapstructure:"level"`
 	} `mapstructure:"logger"`
 	Blizzard struct {
-		ClientID     string `mapstructure:"client_id"`
-		ClientSecret string `mapstructure:"client_secret"`
-		RedirectURL  string `mapstructure:"redirect_url"`
+		ClientID         string        `mapstructure:"client_id"`
+		ClientSecret     string        `mapstructure:"client_secret"`
+		RedirectURL      string        `mapstructure:"redirect_url"`
+		Workers          int           `mapstructure:"workers"`
+		CharacterTimeout time.Duration `mapstructure:"character_timeout"`
+		RateLimit        struct {
+			PerSecond  int `mapstructure:"per_second"`
+			Burst      int `mapstructure:"burst"`
+			PerHour    int `mapstructure:"per_hour"`
+			MaxRetries int `mapstructure:"max_retries"`
+		} `mapstructure:"rate_limit"`
 	} `mapstructure:"blizzard"`
+	Auth struct {
+		URL string `mapstructure:"url"`
+	} `mapstructure:"auth"`
+	Resilience struct {
+		RetryAttempts    uint          `mapstructure:"retry_attempts"`
+		RetryDelay       time.Duration `mapstructure:"retry_delay"`
+		RetryMaxJitter   time.Duration `mapstructure:"retry_max_jitter"`
+		BreakerThreshold int           `mapstructure:"breaker_threshold"`
+		BreakerCooldown  time.Duration `mapstructure:"breaker_cooldown"`
+	} `mapstructure:"resilience"`
 	JWT struct {
 		Secret string `mapstructure:"secret"`
 	} `mapstructure:"jwt"`
+	Profile struct {
+		MaxLevel   int           `mapstructure:"max_level"`
+		RefreshTTL time.Duration `mapstructure:"refresh_ttl"`
+	} `mapstructure:"profile"`
+	Tracing struct {
+		Enabled     bool    `mapstructure:"enabled"`
+		Exporter    string  `mapstructure:"exporter"`
+		Endpoint    string  `mapstructure:"endpoint"`
+		Insecure    bool    `mapstructure:"insecure"`
+		File        string  `mapstructure:"file"`
+		SampleRatio float64 `mapstructure:"sample_ratio"`
+		ServiceName string  `mapstructure:"service_name"`
+	} `mapstructure:"tracing"`
+	Webhooks struct {
+		Enabled         bool          `mapstructure:"enabled"`
+		PollInterval    time.Duration `mapstructure:"poll_interval"`
+		BatchSize       int           `mapstructure:"batch_size"`
+		Workers         int           `mapstructure:"workers"`
+		Timeout         time.Duration `mapstructure:"timeout"`
+		MaxAttempts     int           `mapstructure:"max_attempts"`
+		BackoffBase     time.Duration `mapstructure:"backoff_base"`
+		BackoffMax      time.Duration `mapstructure:"backoff_max"`
+		Retention       time.Duration `mapstructure:"retention"`
+		AllowPrivate    bool          `mapstructure:"allow_private"`
+		ScoreThresholds []float64     `mapstructure:"score_thresholds"`
+	} `mapstructure:"webhooks"`
+	Events struct {
+		Publisher    string        `mapstructure:"publisher"`
+		Channel      string        `mapstructure:"channel"`
+		Retention    time.Duration `mapstructure:"retention"`
+		PollInterval time.Duration `mapstructure:"poll_interval"`
+		BatchSize    int           `mapstructure:"batch_size"`
+	} `mapstructure:"events"`
+	Features struct {
+		ConditionalRequests bool `mapstructure:"conditional_requests"`
+	} `mapstructure:"features"`
 }
 
 func LoadConfig() (*Config, error) {
+	cfg, _, err := load()
+	return cfg, err
+}
+
+// load returns the config together with the YAML file it was read from, empty
+// when only defaults and the environment were used.
+func load() (*Config, string, error) {
 	v := viper.New()
 
 	v.AddConfigPath(".")
@@ -42,20 +124,87 @@ func LoadConfig() (*Config, error) {
 	v.SetConfigType("yaml")
 	v.SetConfigName("config")
 
+	setDefaults(v)
+
 	if err := v.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed reading config: %w", err)
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return nil, "", fmt.Errorf("failed reading config: %w", err)
+		}
 	}
 
-	v.AutomaticEnv()
+	if err := applyEnv(v, os.LookupEnv); err != nil {
+		return nil, "", err
+	}
 
 	var cfg Config
 
 	if err := v.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("failed mapping config to struct: %w", err)
+		return nil, "", fmt.Errorf("failed mapping config to struct: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, "", err
 	}
 
-	log.Printf("Loaded config: DB_HOST=%s, DB_PORT=%d, DB_USER=%s",
-		cfg.DB.Host, cfg.DB.Port, cfg.DB.User)
+	return &cfg, v.ConfigFileUsed(), nil
+}
 
-	return &cfg, nil
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("server.port", 8081)
+	v.SetDefault("server.drain_delay", 5*time.Second)
+	v.SetDefault("server.shutdown_timeout", 5*time.Second)
+	v.SetDefault("grpc.enabled", false)
+	v.SetDefault("grpc.port", 9091)
+	v.SetDefault("db.port", 5432)
+	v.SetDefault("db.sslmode", "disable")
+	v.SetDefault("db.max_conns", 10)
+	v.SetDefault("db.min_conns", 2)
+	v.SetDefault("db.max_conn_lifetime", 10*time.Minute)
+	v.SetDefault("db.max_conn_idle_time", 30*time.Second)
+	v.SetDefault("db.health_check_period", 5*time.Minute)
+	v.SetDefault("db.connect_timeout", 10*time.Second)
+	v.SetDefault("db.connect_attempts", 5)
+	v.SetDefault("db.connect_retry_delay", 2*time.Second)
+	v.SetDefault("db.replica_check_interval", 10*time.Second)
+	v.SetDefault("db.migrations", "apply")
+	v.SetDefault("logger.level", "info")
+	v.SetDefault("profile.max_level", 90)
+	v.SetDefault("profile.refresh_ttl", time.Hour)
+	v.SetDefault("auth.url", "http://auth_service:8080")
+	v.SetDefault("resilience.retry_attempts", 3)
+	v.SetDefault("resilience.retry_delay", 200*time.Millisecond)
+	v.SetDefault("resilience.retry_max_jitter", 200*time.Millisecond)
+	v.SetDefault("resilience.breaker_threshold", 5)
+	v.SetDefault("resilience.breaker_cooldown", 30*time.Second)
+	v.SetDefault("blizzard.rate_limit.per_second", 100)
+	v.SetDefault("blizzard.rate_limit.burst", 10)
+	v.SetDefault("blizzard.rate_limit.per_hour", 36000)
+	v.SetDefault("blizzard.rate_limit.max_retries", 2)
+	v.SetDefault("blizzard.workers", 3)
+	v.SetDefault("blizzard.character_timeout", 10*time.Second)
+	v.SetDefault("features.conditional_requests", true)
+	v.SetDefault("webhooks.enabled", true)
+	v.SetDefault("webhooks.poll_interval", 2*time.Second)
+	v.SetDefault("webhooks.batch_size", 50)
+	v.SetDefault("webhooks.workers", 4)
+	v.SetDefault("webhooks.timeout", 10*time.Second)
+	v.SetDefault("webhooks.max_attempts", 8)
+	v.SetDefault("webhooks.backoff_base", 30*time.Second)
+	v.SetDefault("webhooks.backoff_max", time.Hour)
+	v.SetDefault("webhooks.retention", 7*24*time.Hour)
+	v.SetDefault("webhooks.allow_private", false)
+	v.SetDefault("webhooks.score_thresholds", []float64{1000, 1500, 2000, 2500, 3000})
+	v.SetDefault("events.publisher", "postgres")
+	v.SetDefault("events.channel", "profile_events")
+	v.SetDefault("events.retention", 7*24*time.Hour)
+	v.SetDefault("events.poll_interval", time.Second)
+	v.SetDefault("events.batch_size", 100)
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.exporter", "otlp")
+	v.SetDefault("tracing.endpoint", "localhost:4318")
+	v.SetDefault("tracing.insecure", true)
+	v.SetDefault("tracing.file", "traces.json")
+	v.SetDefault("tracing.sample_ratio", 1.0)
+	v.SetDefault("tracing.service_name", "profile-service")
 }
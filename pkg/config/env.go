@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const secretFileSuffix = "_FILE"
+
+// envAliases keeps the variable names already used in .env and
+// docker-compose working next to the derived ones (db.sslmode -> DB_SSLMODE).
+var envAliases = map[string][]string{
+	"db.sslmode":             {"SSL_MODE"},
+	"blizzard.client_id":     {"CLIENT_ID"},
+	"blizzard.client_secret": {"CLIENT_SECRET"},
+	"blizzard.redirect_url":  {"REDIRECT_URL"},
+	"auth.url":               {"AUTH_SERVICE_URL"},
+}
+
+// applyEnv layers environment variables over defaults and the YAML file, then
+// <NAME>_FILE secret files over both. lookup is os.LookupEnv outside tests.
+func applyEnv(v *viper.Viper, lookup func(string) (string, bool)) error {
+	for _, key := range configKeys(reflect.TypeOf(Config{}), "") {
+		names := append([]string{envName(key)}, envAliases[key]...)
+
+		for _, name := range names {
+			if value, ok := lookup(name); ok {
+				v.Set(key, value)
+				break
+			}
+		}
+
+		for _, name := range names {
+			path, ok := lookup(name + secretFileSuffix)
+			if !ok || path == "" {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("read %s%s for %s: %w", name, secretFileSuffix, key, err)
+			}
+			v.Set(key, strings.TrimSpace(string(data)))
+			break
+		}
+	}
+
+	return nil
+}
+
+func envName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+func configKeys(t reflect.Type, prefix string) []string {
+	keys := make([]string, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, configKeys(field.Type, key)...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func fakeEnv(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+func writeSecret(t *testing.T, value string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte(value), 0o600); err != nil {
+		t.Fatalf("write secret file: %v", err)
+	}
+	return path
+}
+
+func TestApplyEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		key  string
+		want string
+	}{
+		{
+			name: "nested key",
+			env:  map[string]string{"DB_HOST": "db.internal"},
+			key:  "db.host",
+			want: "db.internal",
+		},
+		{
+			name: "deeply nested key",
+			env:  map[string]string{"BLIZZARD_RATE_LIMIT_PER_HOUR": "1000"},
+			key:  "blizzard.rate_limit.per_hour",
+			want: "1000",
+		},
+		{
+			name: "alias",
+			env:  map[string]string{"CLIENT_ID": "from-alias"},
+			key:  "blizzard.client_id",
+			want: "from-alias",
+		},
+		{
+			name: "derived name wins over alias",
+			env:  map[string]string{"DB_SSLMODE": "require", "SSL_MODE": "disable"},
+			key:  "db.sslmode",
+			want: "require",
+		},
+		{
+			name: "secret file wins over plain value",
+			env:  map[string]string{"DB_PASS": "plain", "DB_PASS_FILE": writeSecret(t, "from-file\n")},
+			key:  "db.pass",
+			want: "from-file",
+		},
+		{
+			name: "secret file through alias",
+			env:  map[string]string{"CLIENT_SECRET_FILE": writeSecret(t, "  s3cret  ")},
+			key:  "blizzard.client_secret",
+			want: "s3cret",
+		},
+		{
+			name: "empty secret file path is ignored",
+			env:  map[string]string{"JWT_SECRET": "plain", "JWT_SECRET_FILE": ""},
+			key:  "jwt.secret",
+			want: "plain",
+		},
+		{
+			name: "unset keeps the existing value",
+			env:  map[string]string{},
+			key:  "db.host",
+			want: "from-yaml",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("db.host", "from-yaml")
+
+			if err := applyEnv(v, fakeEnv(tt.env)); err != nil {
+				t.Fatalf("applyEnv: %v", err)
+			}
+			if got := v.GetString(tt.key); got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyEnvSecretFileErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "missing file", path: filepath.Join(dir, "missing")},
+		{name: "unreadable file", path: dir},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyEnv(viper.New(), fakeEnv(map[string]string{"DB_PASS_FILE": tt.path}))
+			if err == nil {
+				t.Fatal("applyEnv succeeded, want an error")
+			}
+			if !strings.Contains(err.Error(), "DB_PASS_FILE for db.pass") {
+				t.Errorf("error = %q, want it to name the variable and key", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const redactedValue = "[REDACTED]"
+
+var secretKeys = []string{"pass", "secret", "password", "token", "tokens", "dsn"}
+
+// Redacted returns the effective config as a nested map keyed like the YAML
+// file, with credentials masked, so it can be logged at startup.
+func (c *Config) Redacted() map[string]any {
+	return redactStruct(reflect.ValueOf(*c))
+}
+
+func redactStruct(v reflect.Value) map[string]any {
+	out := make(map[string]any, v.NumField())
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		field := v.Field(i)
+		switch {
+		case field.Kind() == reflect.Struct:
+			out[tag] = redactStruct(field)
+		case isSecretKey(tag):
+			if field.IsZero() {
+				out[tag] = ""
+			} else {
+				out[tag] = redactedValue
+			}
+		case field.Type() == reflect.TypeOf(time.Duration(0)):
+			out[tag] = time.Duration(field.Int()).String()
+		default:
+			out[tag] = field.Interface()
+		}
+	}
+	return out
+}
+
+func isSecretKey(key string) bool {
+	for _, s := range secretKeys {
+		if key == s || strings.HasSuffix(key, "_"+s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	sslModes  = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	logLevels = []string{"trace", "debug", "info", "warn", "warning", "error", "fatal", "panic"}
+	exporters = []string{"otlp", "stdout", "file"}
+	migModes  = []string{"apply", "verify", "skip"}
+	pubKinds  = []string{"postgres", "memory", "none"}
+)
+
+const (
+	minServiceTokenLength = 16
+
+	// maxStopTime is the stop_grace_period of docker-compose.yml: draining and
+	// shutting down must finish before the container is killed.
+	maxStopTime = 15 * time.Second
+)
+
+// Validate reports every invalid setting at once, named by its config key and
+// the environment variable that overrides it.
+func (c *Config) Validate() error {
+	var errs []error
+	add := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s (%s): %s", key, envName(key), fmt.Sprintf(format, args...)))
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		add("server.port", "must be between 1 and 65535, got %d", c.Server.Port)
+	}
+	if c.Server.DrainDelay < 0 {
+		add("server.drain_delay", "must not be negative")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		add("server.shutdown_timeout", "must be positive")
+	}
+	if stop := c.Server.DrainDelay + c.Server.ShutdownTimeout; stop >= maxStopTime {
+		add("server.shutdown_timeout", "together with server.drain_delay must stay below %s, got %s", maxStopTime, stop)
+	}
+
+	if c.GRPC.Enabled {
+		if c.GRPC.Port < 1 || c.GRPC.Port > 65535 || c.GRPC.Port == c.Server.Port {
+			add("grpc.port", "must be between 1 and 65535 and differ from server.port, got %d", c.GRPC.Port)
+		}
+		if len(c.GRPC.ServiceTokens) == 0 {
+			add("grpc.service_tokens", "at least one is required when grpc is enabled")
+		}
+		for i, entry := range c.GRPC.ServiceTokens {
+			name, token, ok := strings.Cut(entry, ":")
+			if !ok || name == "" || len(token) < minServiceTokenLength {
+				add("grpc.service_tokens", "entry %d must be <service>:<token> with a token of at least %d characters", i, minServiceTokenLength)
+			}
+		}
+	}
+
+	if c.DB.Host == "" {
+		add("db.host", "is required")
+	}
+	if c.DB.Port < 1 || c.DB.Port > 65535 {
+		add("db.port", "must be between 1 and 65535, got %d", c.DB.Port)
+	}
+	if c.DB.User == "" {
+		add("db.user", "is required")
+	}
+	if c.DB.Pass == "" {
+		add("db.pass", "is required")
+	}
+	if c.DB.Name == "" {
+		add("db.name", "is required")
+	}
+	if c.DB.MaxConns < 1 {
+		add("db.max_conns", "must be at least 1, got %d", c.DB.MaxConns)
+	}
+	if c.DB.MinConns < 0 || c.DB.MinConns > c.DB.MaxConns {
+		add("db.min_conns", "must be between 0 and db.max_conns, got %d", c.DB.MinConns)
+	}
+	if c.DB.ConnectAttempts < 1 {
+		add("db.connect_attempts", "must be at least 1, got %d", c.DB.ConnectAttempts)
+	}
+	if c.DB.ConnectTimeout <= 0 {
+		add("db.connect_timeout", "must be positive")
+	}
+	if c.DB.ReplicaDSN != "" && c.DB.ReplicaCheck <= 0 {
+		add("db.replica_check_interval", "must be positive when db.replica_dsn is set")
+	}
+	if !oneOf(c.DB.Migrations, migModes) {
+		add("db.migrations", "must be one of %s, got %q", strings.Join(migModes, ", "), c.DB.Migrations)
+	}
+	if !oneOf(c.DB.SSLMode, sslModes) {
+		add("db.sslmode", "must be one of %s, got %q", strings.Join(sslModes, ", "), c.DB.SSLMode)
+	}
+
+	if !oneOf(strings.ToLower(c.Logger.Level), logLevels) {
+		add("logger.level", "must be one of %s, got %q", strings.Join(logLevels, ", "), c.Logger.Level)
+	}
+
+	if u, err := url.Parse(c.Auth.URL); err != nil || u.Scheme == "" || u.Host == "" {
+		add("auth.url", "must be an absolute URL, got %q", c.Auth.URL)
+	}
+
+	if c.Blizzard.ClientID == "" {
+		add("blizzard.client_id", "is required")
+	}
+	if c.Blizzard.ClientSecret == "" {
+		add("blizzard.client_secret", "is required")
+	}
+	if c.Blizzard.Workers < 1 {
+		add("blizzard.workers", "must be at least 1, got %d", c.Blizzard.Workers)
+	}
+	if c.Blizzard.CharacterTimeout < 0 {
+		add("blizzard.character_timeout", "must not be negative")
+	}
+	if c.Blizzard.RateLimit.PerSecond < 1 {
+		add("blizzard.rate_limit.per_second", "must be at least 1, got %d", c.Blizzard.RateLimit.PerSecond)
+	}
+	if c.Blizzard.RateLimit.Burst < 1 {
+		add("blizzard.rate_limit.burst", "must be at least 1, got %d", c.Blizzard.RateLimit.Burst)
+	}
+	if c.Blizzard.RateLimit.PerHour < 1 {
+		add("blizzard.rate_limit.per_hour", "must be at least 1, got %d", c.Blizzard.RateLimit.PerHour)
+	}
+	if c.Blizzard.RateLimit.MaxRetries < 0 {
+		add("blizzard.rate_limit.max_retries", "must not be negative")
+	}
+
+	if c.Resilience.BreakerThreshold < 1 {
+		add("resilience.breaker_threshold", "must be at least 1, got %d", c.Resilience.BreakerThreshold)
+	}
+
+	if c.JWT.Secret == "" {
+		add("jwt.secret", "is required")
+	}
+
+	if c.Profile.RefreshTTL < 0 {
+		add("profile.refresh_ttl", "must not be negative")
+	}
+
+	if c.Tracing.Enabled {
+		if !oneOf(c.Tracing.Exporter, exporters) {
+			add("tracing.exporter", "must be one of %s, got %q", strings.Join(exporters, ", "), c.Tracing.Exporter)
+		}
+		if c.Tracing.Exporter == "file" && c.Tracing.File == "" {
+			add("tracing.file", "is required for the file exporter")
+		}
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("tracing.sample_ratio", "must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+
+	if c.Webhooks.Enabled {
+		if c.Webhooks.PollInterval <= 0 {
+			add("webhooks.poll_interval", "must be positive")
+		}
+		if c.Webhooks.BatchSize < 1 {
+			add("webhooks.batch_size", "must be at least 1, got %d", c.Webhooks.BatchSize)
+		}
+		if c.Webhooks.Workers < 1 {
+			add("webhooks.workers", "must be at least 1, got %d", c.Webhooks.Workers)
+		}
+		if c.Webhooks.Timeout <= 0 {
+			add("webhooks.timeout", "must be positive")
+		}
+		if c.Webhooks.MaxAttempts < 1 {
+			add("webhooks.max_attempts", "must be at least 1, got %d", c.Webhooks.MaxAttempts)
+		}
+		if c.Webhooks.BackoffBase <= 0 || c.Webhooks.BackoffMax < c.Webhooks.BackoffBase {
+			add("webhooks.backoff_max", "must be at least webhooks.backoff_base, and both positive")
+		}
+	}
+
+	if !oneOf(c.Events.Publisher, pubKinds) {
+		add("events.publisher", "must be one of %s, got %q", strings.Join(pubKinds, ", "), c.Events.Publisher)
+	}
+	if c.Events.Publisher == "postgres" && c.Events.Channel == "" {
+		add("events.channel", "is required for the postgres publisher")
+	}
+	if c.Events.PollInterval <= 0 {
+		add("events.poll_interval", "must be positive")
+	}
+	if c.Events.BatchSize < 1 {
+		add("events.batch_size", "must be at least 1, got %d", c.Events.BatchSize)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+}
+
+func oneOf(value string, allowed []string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
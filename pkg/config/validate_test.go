@@ -0,0 +1,124 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func validConfig(t *testing.T) *Config {
+	t.Helper()
+
+	v := viper.New()
+	setDefaults(v)
+	v.Set("db.host", "localhost")
+	v.Set("db.user", "profile")
+	v.Set("db.pass", "profile")
+	v.Set("db.name", "profile")
+	v.Set("blizzard.client_id", "client")
+	v.Set("blizzard.client_secret", "secret")
+	v.Set("jwt.secret", "jwt")
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return &cfg
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+		want   []string
+	}{
+		{
+			name:   "valid",
+			mutate: func(*Config) {},
+		},
+		{
+			name: "missing credentials",
+			mutate: func(c *Config) {
+				c.DB.Pass = ""
+				c.JWT.Secret = ""
+				c.Blizzard.ClientID = ""
+				c.Blizzard.ClientSecret = ""
+			},
+			want: []string{
+				"db.pass (DB_PASS): is required",
+				"blizzard.client_id (BLIZZARD_CLIENT_ID): is required",
+				"blizzard.client_secret (BLIZZARD_CLIENT_SECRET): is required",
+				"jwt.secret (JWT_SECRET): is required",
+			},
+		},
+		{
+			name:   "port out of range",
+			mutate: func(c *Config) { c.Server.Port = 70000 },
+			want:   []string{"server.port (SERVER_PORT): must be between 1 and 65535, got 70000"},
+		},
+		{
+			name:   "no shutdown timeout",
+			mutate: func(c *Config) { c.Server.ShutdownTimeout = 0 },
+			want:   []string{"server.shutdown_timeout (SERVER_SHUTDOWN_TIMEOUT): must be positive"},
+		},
+		{
+			name: "drain and shutdown past the stop grace period",
+			mutate: func(c *Config) {
+				c.Server.DrainDelay = 10 * time.Second
+				c.Server.ShutdownTimeout = 5 * time.Second
+			},
+			want: []string{"server.shutdown_timeout (SERVER_SHUTDOWN_TIMEOUT): together with server.drain_delay must stay below 15s, got 15s"},
+		},
+		{
+			name:   "unknown sslmode",
+			mutate: func(c *Config) { c.DB.SSLMode = "sometimes" },
+			want:   []string{`db.sslmode (DB_SSLMODE): must be one of disable, allow, prefer, require, verify-ca, verify-full, got "sometimes"`},
+		},
+		{
+			name:   "unknown migrations mode",
+			mutate: func(c *Config) { c.DB.Migrations = "auto" },
+			want:   []string{`db.migrations (DB_MIGRATIONS): must be one of apply, verify, skip, got "auto"`},
+		},
+		{
+			name: "grpc without service tokens",
+			mutate: func(c *Config) {
+				c.GRPC.Enabled = true
+				c.GRPC.ServiceTokens = []string{"worker:short"}
+			},
+			want: []string{"grpc.service_tokens (GRPC_SERVICE_TOKENS): entry 0 must be <service>:<token> with a token of at least 16 characters"},
+		},
+		{
+			name:   "relative auth url",
+			mutate: func(c *Config) { c.Auth.URL = "auth_service:8080" },
+			want:   []string{`auth.url (AUTH_URL): must be an absolute URL, got "auth_service:8080"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			tt.mutate(cfg)
+
+			err := cfg.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Validate succeeded, want an error")
+			}
+
+			lines := strings.Split(err.Error(), "\n")
+			if lines[0] != "invalid config:" {
+				t.Errorf("first line = %q, want the invalid config header", lines[0])
+			}
+			if got := lines[1:]; strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("errors =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
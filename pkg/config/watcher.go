@@ -0,0 +1,129 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// Watcher reloads the config on SIGHUP or when the YAML file changes. Only
+// the settings copied in applyReloadable change at runtime; everything else
+// keeps its startup value until the process restarts.
+type Watcher struct {
+	reload  sync.Mutex
+	mu      sync.Mutex
+	current *Config
+	subs    []func(*Config)
+	log     *logrus.Logger
+}
+
+func NewWatcher(cfg *Config, log *logrus.Logger) *Watcher {
+	return &Watcher{
+		current: cfg,
+		log:     log,
+	}
+}
+
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Subscribe registers fn to receive every accepted config. Subscribers get a
+// fresh copy and are called one at a time, in registration order.
+func (w *Watcher) Subscribe(fn func(*Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subs = append(w.subs, fn)
+}
+
+// Start watches for reload triggers until ctx is done.
+func (w *Watcher) Start(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	changed := make(chan struct{}, 1)
+	if _, path, err := load(); err == nil && path != "" {
+		v := viper.New()
+		v.SetConfigFile(path)
+		v.OnConfigChange(func(fsnotify.Event) {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+		v.WatchConfig()
+		w.log.WithField("file", path).Info("watching config for changes")
+	}
+
+	go func() {
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				w.log.Info("SIGHUP received, reloading config")
+				w.Reload()
+			case <-changed:
+				w.log.Info("config file changed, reloading config")
+				w.Reload()
+			}
+		}
+	}()
+}
+
+// Reload reads the config again and hands the reloadable part to subscribers.
+// An invalid config is rejected and the current one stays active.
+func (w *Watcher) Reload() bool {
+	w.reload.Lock()
+	defer w.reload.Unlock()
+
+	loaded, _, err := load()
+	if err != nil {
+		w.log.WithError(err).Error("config reload rejected")
+		return false
+	}
+
+	current := w.Current()
+	next := *current
+	applyReloadable(&next, loaded)
+
+	if !reflect.DeepEqual(next, *loaded) {
+		w.log.Warn("config reload contains settings that need a restart, they were ignored")
+	}
+	if reflect.DeepEqual(next, *current) {
+		w.log.Info("config reloaded, nothing changed")
+		return true
+	}
+
+	w.mu.Lock()
+	w.current = &next
+	subs := append([]func(*Config){}, w.subs...)
+	w.mu.Unlock()
+
+	for _, fn := range subs {
+		cfg := next
+		fn(&cfg)
+	}
+
+	w.log.WithField("config", next.Redacted()).Info("config reloaded")
+	return true
+}
+
+func applyReloadable(dst, src *Config) {
+	dst.Logger.Level = src.Logger.Level
+	dst.Blizzard.RateLimit = src.Blizzard.RateLimit
+	dst.Blizzard.Workers = src.Blizzard.Workers
+	dst.Blizzard.CharacterTimeout = src.Blizzard.CharacterTimeout
+	dst.Profile.RefreshTTL = src.Profile.RefreshTTL
+	dst.Features = src.Features
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// newTestWatcher loads the config from a config.yaml in a fresh working
+// directory, with the required credentials in the environment, and returns a
+// watcher on it together with a function that rewrites the file.
+func newTestWatcher(t *testing.T, yaml string) (*Watcher, func(string)) {
+	t.Helper()
+
+	for name, value := range map[string]string{
+		"DB_HOST":                "localhost",
+		"DB_USER":                "profile",
+		"DB_PASS":                "profile",
+		"DB_NAME":                "profile",
+		"BLIZZARD_CLIENT_ID":     "client",
+		"BLIZZARD_CLIENT_SECRET": "secret",
+		"JWT_SECRET":             "jwt",
+	} {
+		t.Setenv(name, value)
+	}
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	write := func(yaml string) {
+		if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+	}
+	write(yaml)
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return NewWatcher(cfg, log), write
+}
+
+func TestWatcherReloadNotifiesSubscribers(t *testing.T) {
+	w, write := newTestWatcher(t, `
+logger:
+  level: info
+blizzard:
+  workers: 2
+`)
+
+	var order []string
+	var got []*Config
+	for _, name := range []string{"first", "second"} {
+		w.Subscribe(func(c *Config) {
+			order = append(order, name)
+			got = append(got, c)
+			// Subscribers own their copy.
+			c.Blizzard.Workers = 99
+		})
+	}
+
+	write(`
+logger:
+  level: debug
+blizzard:
+  workers: 8
+`)
+	if !w.Reload() {
+		t.Fatal("Reload rejected a valid config")
+	}
+
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Fatalf("subscribers called as %v, want [first second]", order)
+	}
+	if got[0] == got[1] {
+		t.Error("subscribers shared one config")
+	}
+	cur := w.Current()
+	if cur.Logger.Level != "debug" || cur.Blizzard.Workers != 8 {
+		t.Errorf("current = level %s, %d workers, want debug, 8", cur.Logger.Level, cur.Blizzard.Workers)
+	}
+
+	// Reloading an unchanged file bothers nobody.
+	order = nil
+	if !w.Reload() {
+		t.Fatal("Reload rejected an unchanged config")
+	}
+	if len(order) != 0 {
+		t.Errorf("subscribers called %d times for an unchanged config", len(order))
+	}
+}
+
+func TestWatcherReloadRejectsInvalidConfig(t *testing.T) {
+	w, write := newTestWatcher(t, `
+blizzard:
+  workers: 4
+`)
+	before := w.Current()
+
+	called := false
+	w.Subscribe(func(*Config) { called = true })
+
+	write(`
+blizzard:
+  workers: 0
+`)
+	if w.Reload() {
+		t.Fatal("Reload accepted blizzard.workers: 0")
+	}
+	if called {
+		t.Error("subscriber received a rejected config")
+	}
+	if w.Current() != before || before.Blizzard.Workers != 4 {
+		t.Errorf("current config replaced by a rejected reload: %d workers", w.Current().Blizzard.Workers)
+	}
+}
+
+func TestWatcherReloadKeepsRestartOnlySettings(t *testing.T) {
+	w, write := newTestWatcher(t, `
+server:
+  port: 8081
+logger:
+  level: info
+`)
+
+	var got *Config
+	w.Subscribe(func(c *Config) { got = c })
+
+	write(`
+server:
+  port: 9000
+db:
+  max_conns: 50
+logger:
+  level: warn
+`)
+	if !w.Reload() {
+		t.Fatal("Reload rejected a valid config")
+	}
+
+	if got == nil {
+		t.Fatal("subscriber not called for a reloadable change")
+	}
+	for name, c := range map[string]*Config{"current": w.Current(), "subscriber": got} {
+		if c.Logger.Level != "warn" {
+			t.Errorf("%s logger.level = %s, want warn", name, c.Logger.Level)
+		}
+		if c.Server.Port != 8081 || c.DB.MaxConns != 10 {
+			t.Errorf("%s picked up restart-only settings: port %d, max_conns %d", name, c.Server.Port, c.DB.MaxConns)
+		}
+	}
+}
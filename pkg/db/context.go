@@ -0,0 +1,17 @@
+package dbpool
+
+import "context"
+
+type primaryKey struct{}
+
+// WithPrimary marks ctx so repository reads skip the replica. Use it for reads
+// that must see a write the same request just committed.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// UsePrimary reports whether ctx was marked by WithPrimary.
+func UsePrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
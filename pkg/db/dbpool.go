@@ -3,58 +3,42 @@ package dbpool
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/url"
 	"profile-service/pkg/config"
+	"strconv"
 	"time"
 
+	"github.com/exaring/otelpgx"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sirupsen/logrus"
 )
 
 func BuildDSN(cfg *config.Config) string {
-	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
-		cfg.DB.User,
-		cfg.DB.Pass,
-		cfg.DB.Host,
-		cfg.DB.Port,
-		cfg.DB.Name,
-		cfg.DB.SSLMode,
-	)
-	return dsn
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.DB.User, cfg.DB.Pass),
+		Host:     net.JoinHostPort(cfg.DB.Host, strconv.Itoa(cfg.DB.Port)),
+		Path:     "/" + cfg.DB.Name,
+		RawQuery: url.Values{"sslmode": {cfg.DB.SSLMode}}.Encode(),
+	}
+	return dsn.String()
 }
 
 func InitDBPool(dsn string, cfg *config.Config, log *logrus.Logger, ctx context.Context) (*pgxpool.Pool, error) {
-	if dsn == "" {
-		return nil, fmt.Errorf("dsn string is empty")
-	}
-
-	conf, err := pgxpool.ParseConfig(dsn)
+	conf, err := poolConfig(dsn, cfg)
 	if err != nil {
 		log.WithError(err).Error("failed parse dsn db config")
 		return nil, err
 	}
 
-	conf.MaxConnIdleTime = 30 * time.Second
-	conf.MaxConnLifetime = 10 * time.Minute
-	conf.MaxConns = 10
-	conf.MinConns = 2
-	conf.HealthCheckPeriod = 5 * time.Minute
-
-	connCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
-
+	attempts := cfg.DB.ConnectAttempts
 	var pool *pgxpool.Pool
-	for i := 0; i < 5; i++ {
-		pool, err = pgxpool.NewWithConfig(connCtx, conf)
+	for i := 0; i < attempts; i++ {
+		pool, err = connect(ctx, conf, cfg.DB.ConnectTimeout)
 		if err != nil {
-			log.WithError(err).Errorf("failed create new pool, attempts: %d", i+1)
-			time.Sleep(2 * time.Second)
-			continue
-		}
-
-		if err = pool.Ping(ctx); err != nil {
-			log.WithError(err).Warnf("failed ping db")
-			pool.Close()
-			time.Sleep(2 * time.Second)
+			log.WithError(err).Warnf("failed connect db, attempts: %d", i+1)
+			time.Sleep(cfg.DB.ConnectRetryDelay)
 			continue
 		}
 
@@ -62,6 +46,43 @@ func InitDBPool(dsn string, cfg *config.Config, log *logrus.Logger, ctx context.
 		return pool, nil
 	}
 
-	log.WithError(err).Errorf("failed create db pool after 5 tries: %v", err)
+	log.WithError(err).Errorf("failed create db pool after %d tries: %v", attempts, err)
 	return nil, err
 }
+
+func poolConfig(dsn string, cfg *config.Config) (*pgxpool.Config, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("dsn string is empty")
+	}
+
+	conf, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	conf.MaxConnIdleTime = cfg.DB.MaxConnIdleTime
+	conf.MaxConnLifetime = cfg.DB.MaxConnLifetime
+	conf.MaxConns = cfg.DB.MaxConns
+	conf.MinConns = cfg.DB.MinConns
+	conf.HealthCheckPeriod = cfg.DB.HealthCheckPeriod
+	conf.ConnConfig.Tracer = otelpgx.NewTracer()
+
+	return conf, nil
+}
+
+func connect(ctx context.Context, conf *pgxpool.Config, timeout time.Duration) (*pgxpool.Pool, error) {
+	connCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	pool, err := pgxpool.NewWithConfig(connCtx, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pool.Ping(connCtx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return pool, nil
+}
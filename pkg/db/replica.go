@@ -0,0 +1,86 @@
+package dbpool
+
+import (
+	"context"
+	"profile-service/pkg/config"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// Replica is an optional read-only pool. It is only handed out while its last
+// health check passed, so callers fall back to the primary otherwise.
+type Replica struct {
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+	timeout time.Duration
+	log     *logrus.Logger
+}
+
+// NewReplica creates the replica pool without requiring it to be reachable;
+// Monitor marks it healthy once a ping succeeds.
+func NewReplica(ctx context.Context, dsn string, cfg *config.Config, log *logrus.Logger) (*Replica, error) {
+	conf, err := poolConfig(dsn, cfg)
+	if err != nil {
+		log.WithError(err).Error("failed parse replica dsn")
+		return nil, err
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, conf)
+	if err != nil {
+		log.WithError(err).Error("failed create replica pool")
+		return nil, err
+	}
+
+	r := &Replica{pool: pool, timeout: cfg.DB.ConnectTimeout, log: log}
+	r.check(ctx)
+	return r, nil
+}
+
+// Pool returns the replica pool, or nil while it is unhealthy.
+func (r *Replica) Pool() *pgxpool.Pool {
+	if r == nil || !r.healthy.Load() {
+		return nil
+	}
+	return r.pool
+}
+
+func (r *Replica) Raw() *pgxpool.Pool {
+	return r.pool
+}
+
+func (r *Replica) Monitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.check(ctx)
+			}
+		}
+	}()
+}
+
+func (r *Replica) Close() {
+	r.pool.Close()
+}
+
+func (r *Replica) check(ctx context.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	err := r.pool.Ping(pingCtx)
+	healthy := err == nil
+	if r.healthy.Swap(healthy) != healthy {
+		if healthy {
+			r.log.Info("read replica is healthy, routing reads to it")
+		} else {
+			r.log.WithError(err).Warn("read replica is unhealthy, routing reads to primary")
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type MythScoreDto struct {
 	Current struct {
 		Rating float64 `json:"rating"`
@@ -36,8 +38,9 @@ type BlizzardProfileResponse struct {
 }
 
 type CharacterDetailsResponse struct {
-	ID   int `json:"id"`
-	Spec struct {
+	ID    int `json:"id"`
+	Level int `json:"level"`
+	Spec  struct {
 		Name string `json:"name"`
 	} `json:"active_spec"`
 	Guild struct {
@@ -54,22 +57,85 @@ type CharacterDetailsResponse struct {
 	Ilvl int `json:"average_item_level"`
 }
 
+type RealmIndexResponse struct {
+	Realms []struct {
+		ID   int               `json:"id"`
+		Slug string            `json:"slug"`
+		Name map[string]string `json:"name"`
+	} `json:"realms"`
+}
+
+type GuildRosterResponse struct {
+	Guild struct {
+		ID      int    `json:"id"`
+		Name    string `json:"name"`
+		Faction struct {
+			Name string `json:"name"`
+		} `json:"faction"`
+		Realm struct {
+			Name string `json:"name"`
+			Slug string `json:"slug"`
+		} `json:"realm"`
+	} `json:"guild"`
+	Members []struct {
+		Character struct {
+			ID    int    `json:"id"`
+			Name  string `json:"name"`
+			Level int    `json:"level"`
+			Realm struct {
+				Slug string `json:"slug"`
+			} `json:"realm"`
+			PlayableClass struct {
+				ID int `json:"id"`
+			} `json:"playable_class"`
+		} `json:"character"`
+		Rank int `json:"rank"`
+	} `json:"members"`
+}
+
+type ClientTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
 type ProfileResponse struct {
 	BlizzardID string
 	Battletag  string
 	Characters []CharacterResponse
+	SyncedAt   time.Time
+	Degraded   bool
 }
 
 type CharacterResponse struct {
-	Name        string  `json:"name" db:"name"`
-	Realm       string  `json:"realm" db:"realm"`
-	Race        string  `json:"race" db:"race"`
-	Faction     string  `json:"faction" db:"faction"`
-	Class       string  `json:"class" db:"class"`
-	Spec        string  `json:"spec" db:"spec"`
-	Lvl         int     `json:"lvl" db:"lvl"`
-	Ilvl        int     `json:"ilvl" db:"ilvl"`
-	Guild       string  `json:"guild" db:"guild"`
-	MythicScore float64 `json:"mythic_score" db:"mythic_score"`
-	IsMain      bool    `json:"is_main" db:"is_main"`
+	Name         string  `json:"name" db:"name"`
+	Realm        string  `json:"realm" db:"realm"`
+	Race         string  `json:"race" db:"race"`
+	Faction      string  `json:"faction" db:"faction"`
+	Class        string  `json:"class" db:"class"`
+	Spec         string  `json:"spec" db:"spec"`
+	Lvl          int     `json:"lvl" db:"lvl"`
+	Ilvl         int     `json:"ilvl" db:"ilvl"`
+	Guild        string  `json:"guild" db:"guild"`
+	MythicScore  float64 `json:"mythic_score" db:"mythic_score"`
+	IsMain       bool    `json:"is_main" db:"is_main"`
+	Hidden       bool    `json:"hidden" db:"hidden"`
+	DisplayOrder *int    `json:"display_order,omitempty" db:"display_order"`
+	Note         string  `json:"note" db:"note"`
+}
+
+type PreferenceRequest struct {
+	Hidden       bool   `json:"hidden"`
+	DisplayOrder *int   `json:"display_order"`
+	Note         string `json:"note"`
+}
+
+type WebhookSubscriptionRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	GuildID    *int     `json:"guild_id"`
+}
+
+type MainLookupRequest struct {
+	BlizzardIDs []string `json:"blizzard_ids"`
+	Battletags  []string `json:"battletags"`
 }
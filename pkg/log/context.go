@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type ctxKey struct{}
+
+const RequestIDField = "request_id"
+
+// WithEntry stores a request-scoped entry in ctx.
+func WithEntry(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, ctxKey{}, entry)
+}
+
+// FromContext returns the request-scoped entry from ctx, or a plain entry of
+// fallback when the call did not come through the HTTP middleware.
+func FromContext(ctx context.Context, fallback *logrus.Logger) *logrus.Entry {
+	if entry, ok := ctx.Value(ctxKey{}).(*logrus.Entry); ok {
+		return entry
+	}
+	return logrus.NewEntry(fallback)
+}
+
+func RequestID(ctx context.Context) string {
+	if entry, ok := ctx.Value(ctxKey{}).(*logrus.Entry); ok {
+		if id, ok := entry.Data[RequestIDField].(string); ok {
+			return id
+		}
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestFromContext(t *testing.T) {
+	fallback := logrus.New()
+	scoped := logrus.New().WithField(RequestIDField, "req-1")
+
+	if got := FromContext(context.Background(), fallback); got.Logger != fallback || len(got.Data) != 0 {
+		t.Errorf("FromContext without an entry = %v, want a plain entry of the fallback", got.Data)
+	}
+	if got := FromContext(WithEntry(context.Background(), scoped), fallback); got != scoped {
+		t.Errorf("FromContext did not return the stored entry")
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	log := logrus.New()
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{name: "no entry", ctx: context.Background()},
+		{name: "entry without an id", ctx: WithEntry(context.Background(), log.WithField("grpc_method", "/x"))},
+		{name: "entry with an id", ctx: WithEntry(context.Background(), log.WithField(RequestIDField, "req-1")), want: "req-1"},
+	}
+
+	for _, tt := range tests {
+		if got := RequestID(tt.ctx); got != tt.want {
+			t.Errorf("%s: RequestID = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
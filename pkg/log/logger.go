@@ -9,15 +9,10 @@ import (
 func InitLogger(cfg *config.Config) *logrus.Logger {
 	log := logrus.New()
 
-	logLvl, err := logrus.ParseLevel(cfg.Logger.Level)
-	if err != nil {
-		log.SetLevel(logrus.InfoLevel)
-		log.Warnf("failed parse logger level: %v", err)
-	} else {
-		log.SetLevel(logLvl)
-	}
+	SetLevel(log, cfg.Logger.Level)
 
 	log.SetFormatter(&logrus.JSONFormatter{})
+	log.AddHook(redactHook{})
 
 	log.WithFields(logrus.Fields{
 		"service": "profile",
@@ -25,3 +20,13 @@ func InitLogger(cfg *config.Config) *logrus.Logger {
 
 	return log
 }
+
+func SetLevel(log *logrus.Logger, level string) {
+	logLvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		log.SetLevel(logrus.InfoLevel)
+		log.Warnf("failed parse logger level: %v", err)
+		return
+	}
+	log.SetLevel(logLvl)
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	sensitiveKeys = []string{"authorization", "token", "secret", "password", "pass", "cookie", "jwt"}
+
+	sensitivePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9\-._~+/]+=*`),
+		regexp.MustCompile(`(?i)((?:access_token|client_secret|token|password)=)[^&\s"]+`),
+		regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+`),
+	}
+)
+
+// redactHook masks credentials in fields and messages before any formatter
+// sees the entry.
+type redactHook struct{}
+
+func (redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (redactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = Redact(entry.Message)
+
+	data := make(logrus.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		switch {
+		case isSensitiveKey(key):
+			data[key] = redacted
+		case key == logrus.ErrorKey:
+			if err, ok := value.(error); ok {
+				data[key] = Redact(err.Error())
+			} else {
+				data[key] = value
+			}
+		default:
+			switch v := value.(type) {
+			case string:
+				data[key] = Redact(v)
+			case fmt.Stringer:
+				data[key] = Redact(v.String())
+			default:
+				data[key] = value
+			}
+		}
+	}
+	entry.Data = data
+
+	return nil
+}
+
+func Redact(s string) string {
+	for _, re := range sensitivePatterns {
+		s = re.ReplaceAllStringFunc(s, func(match string) string {
+			if sub := re.FindStringSubmatch(match); len(sub) > 1 {
+				return sub[1] + redacted
+			}
+			return redacted
+		})
+	}
+	return s
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range sensitiveKeys {
+		if key == k || strings.HasSuffix(key, "_"+k) || strings.HasPrefix(key, k+"_") {
+			return true
+		}
+	}
+	return false
+}
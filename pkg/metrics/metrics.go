@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "profile_service"
+
+type Metrics struct {
+	registry         *prometheus.Registry
+	httpDuration     *prometheus.HistogramVec
+	upstreamRequests *prometheus.CounterVec
+	upstreamDuration *prometheus.HistogramVec
+	rateLimitWait    prometheus.Histogram
+	workerQueue      prometheus.Gauge
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of handled HTTP requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		upstreamRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "upstream_requests_total",
+			Help:      "Outgoing calls by upstream service, endpoint and status.",
+		}, []string{"service", "endpoint", "status"}),
+		upstreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upstream_request_duration_seconds",
+			Help:      "Latency of outgoing calls by upstream service and endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"service", "endpoint"}),
+		rateLimitWait: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "blizzard_rate_limit_wait_seconds",
+			Help:      "Time Blizzard calls spent waiting for the rate limiter.",
+			Buckets:   []float64{0.001, 0.01, 0.05, 0.1, 0.5, 1, 5, 30, 60},
+		}),
+		workerQueue: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "character_fetch_queue_depth",
+			Help:      "Characters waiting for a free fetch worker.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.upstreamRequests,
+		m.upstreamDuration,
+		m.rateLimitWait,
+		m.workerQueue,
+	)
+
+	return m
+}
+
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.httpDuration.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+func (m *Metrics) ObserveRateLimitWait(d time.Duration) {
+	m.rateLimitWait.Observe(d.Seconds())
+}
+
+func (m *Metrics) WorkerQueue() prometheus.Gauge {
+	return m.workerQueue
+}
+
+// Transport counts and times every round trip to service. endpoint maps a
+// request to a low-cardinality label, so URLs with names in them must be
+// collapsed by the caller.
+func (m *Metrics) Transport(base http.RoundTripper, service string, endpoint func(*http.Request) string) http.RoundTripper {
+	return &transport{base: base, service: service, endpoint: endpoint, m: m}
+}
+
+type transport struct {
+	base     http.RoundTripper
+	service  string
+	endpoint func(*http.Request) string
+	m        *Metrics
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := t.endpoint(req)
+	start := time.Now()
+
+	resp, err := t.base.RoundTrip(req)
+
+	t.m.upstreamDuration.WithLabelValues(t.service, endpoint).Observe(time.Since(start).Seconds())
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	t.m.upstreamRequests.WithLabelValues(t.service, endpoint, status).Inc()
+
+	return resp, err
+}
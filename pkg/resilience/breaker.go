@@ -0,0 +1,131 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker trips after threshold consecutive failures and rejects calls for
+// cooldown. After that a single probe call is let through: success closes the
+// breaker again, failure re-opens it for another cooldown.
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	log       *logrus.Logger
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewBreaker(name string, threshold int, cooldown time.Duration, log *logrus.Logger) *Breaker {
+	return &Breaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		log:       log,
+	}
+}
+
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.cooldown {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != StateClosed {
+		b.log.WithField("upstream", b.name).Info("circuit breaker closed")
+	}
+	b.state = StateClosed
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		if b.state != StateOpen {
+			b.log.WithFields(logrus.Fields{
+				"upstream": b.name,
+				"failures": b.failures,
+			}).Warn("circuit breaker opened")
+		}
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// release gives back a half-open probe slot when the caller gave up before
+// the upstream answered, so the outcome says nothing about its health.
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
@@ -0,0 +1,95 @@
+package resilience
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func newTestBreaker(threshold int, cooldown time.Duration) *Breaker {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return NewBreaker("test", threshold, cooldown, log)
+}
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	b := newTestBreaker(3, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		b.Failure()
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("state = %s after 2 of 3 failures", b.State())
+	}
+
+	// A success resets the count: only consecutive failures trip the breaker.
+	b.Success()
+	b.Failure()
+	b.Failure()
+	if b.State() != StateClosed {
+		t.Fatalf("state = %s after a success broke the run of failures", b.State())
+	}
+
+	b.Failure()
+	if b.State() != StateOpen {
+		t.Fatalf("state = %s after 3 consecutive failures", b.State())
+	}
+	for i := 0; i < 3; i++ {
+		if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Allow on an open breaker = %v, want ErrCircuitOpen", err)
+		}
+	}
+}
+
+func TestBreakerHalfOpenLetsOneProbeThrough(t *testing.T) {
+	tests := []struct {
+		name      string
+		outcome   func(b *Breaker)
+		wantState State
+	}{
+		{name: "probe succeeds", outcome: (*Breaker).Success, wantState: StateClosed},
+		{name: "probe fails", outcome: (*Breaker).Failure, wantState: StateOpen},
+		{name: "probe abandoned", outcome: (*Breaker).release, wantState: StateHalfOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBreaker(1, 20*time.Millisecond)
+			b.Failure()
+			if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("Allow before the cooldown = %v", err)
+			}
+
+			time.Sleep(30 * time.Millisecond)
+			if b.State() != StateHalfOpen {
+				t.Fatalf("state = %s after the cooldown", b.State())
+			}
+			if err := b.Allow(); err != nil {
+				t.Fatalf("probe rejected: %v", err)
+			}
+			if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("second call during the probe = %v, want ErrCircuitOpen", err)
+			}
+
+			tt.outcome(b)
+			if b.State() != tt.wantState {
+				t.Fatalf("state = %s, want %s", b.State(), tt.wantState)
+			}
+
+			err := b.Allow()
+			switch tt.wantState {
+			case StateOpen:
+				if !errors.Is(err, ErrCircuitOpen) {
+					t.Errorf("Allow after a failed probe = %v, want a new cooldown", err)
+				}
+			default:
+				if err != nil {
+					t.Errorf("Allow = %v, want the call let through", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package resilience
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/avast/retry-go"
+)
+
+type RetryPolicy struct {
+	Attempts  uint
+	Delay     time.Duration
+	MaxJitter time.Duration
+}
+
+// Transport guards an upstream with a circuit breaker and retries idempotent
+// requests that fail with a network error or a 5xx status. The last 5xx
+// response is returned as is so callers still see the upstream status.
+// A request body is only resent when it can be replayed through GetBody.
+type Transport struct {
+	base    http.RoundTripper
+	breaker *Breaker
+	policy  RetryPolicy
+}
+
+func NewTransport(base http.RoundTripper, breaker *Breaker, policy RetryPolicy) *Transport {
+	if policy.Attempts == 0 {
+		policy.Attempts = 1
+	}
+	return &Transport{
+		base:    base,
+		breaker: breaker,
+		policy:  policy,
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !idempotent(req.Method) || !replayable(req) {
+		return t.roundTrip(req)
+	}
+
+	var resp *http.Response
+	var attempt uint
+	err := retry.Do(
+		func() error {
+			attempt++
+			try := req
+			if attempt > 1 && req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return retry.Unrecoverable(err)
+				}
+				try = req.Clone(req.Context())
+				try.Body = body
+			}
+
+			r, err := t.roundTrip(try)
+			if err != nil {
+				return err
+			}
+			if r.StatusCode >= http.StatusInternalServerError && attempt < t.policy.Attempts {
+				io.Copy(io.Discard, r.Body)
+				r.Body.Close()
+				return fmt.Errorf("upstream %s responded %d", t.breaker.Name(), r.StatusCode)
+			}
+			resp = r
+			return nil
+		},
+		retry.Attempts(t.policy.Attempts),
+		retry.Delay(t.policy.Delay),
+		retry.MaxJitter(t.policy.MaxJitter),
+		retry.DelayType(retry.CombineDelay(retry.BackOffDelay, retry.RandomDelay)),
+		retry.RetryIf(func(err error) bool {
+			return err != ErrCircuitOpen && retry.IsRecoverable(err) && req.Context().Err() == nil
+		}),
+		retry.Context(req.Context()),
+		retry.LastErrorOnly(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (t *Transport) roundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	switch {
+	case err != nil:
+		if req.Context().Err() != nil {
+			t.breaker.release()
+		} else {
+			t.breaker.Failure()
+		}
+		return nil, err
+	case resp.StatusCode >= http.StatusInternalServerError:
+		t.breaker.Failure()
+	default:
+		t.breaker.Success()
+	}
+	return resp, nil
+}
+
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
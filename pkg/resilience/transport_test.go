@@ -0,0 +1,147 @@
+package resilience
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func statusResponse(status int) *http.Response {
+	return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(""))}
+}
+
+var testPolicy = RetryPolicy{Attempts: 3, Delay: time.Millisecond, MaxJitter: time.Millisecond}
+
+func TestTransportRetries(t *testing.T) {
+	errNetwork := errors.New("connection reset")
+
+	tests := []struct {
+		name       string
+		method     string
+		results    []int // 0 stands for a network error
+		wantCalls  int
+		wantStatus int
+		wantErr    error
+	}{
+		{name: "5xx then success", method: http.MethodGet, results: []int{502, 200}, wantCalls: 2, wantStatus: 200},
+		{name: "network error then success", method: http.MethodGet, results: []int{0, 200}, wantCalls: 2, wantStatus: 200},
+		{name: "5xx until the limit", method: http.MethodGet, results: []int{500, 502, 503, 200}, wantCalls: 3, wantStatus: 503},
+		{name: "network errors until the limit", method: http.MethodGet, results: []int{0, 0, 0, 200}, wantCalls: 3, wantErr: errNetwork},
+		{name: "4xx not retried", method: http.MethodGet, results: []int{404, 200}, wantCalls: 1, wantStatus: 404},
+		{name: "POST 5xx not retried", method: http.MethodPost, results: []int{500, 200}, wantCalls: 1, wantStatus: 500},
+		{name: "POST network error not retried", method: http.MethodPost, results: []int{0, 200}, wantCalls: 1, wantErr: errNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			tr := NewTransport(roundTripFunc(func(*http.Request) (*http.Response, error) {
+				status := tt.results[calls]
+				calls++
+				if status == 0 {
+					return nil, errNetwork
+				}
+				return statusResponse(status), nil
+			}), newTestBreaker(100, time.Hour), testPolicy)
+
+			req, _ := http.NewRequest(tt.method, "http://upstream.test/", nil)
+			resp, err := tr.RoundTrip(req)
+
+			if calls != tt.wantCalls {
+				t.Errorf("upstream called %d times, want %d", calls, tt.wantCalls)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RoundTrip: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestTransportFailsFastWhileOpen(t *testing.T) {
+	b := newTestBreaker(2, time.Hour)
+	calls := 0
+	tr := NewTransport(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		calls++
+		return statusResponse(http.StatusServiceUnavailable), nil
+	}), b, testPolicy)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://upstream.test/", nil)
+	if _, err := tr.RoundTrip(req); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want the retries to trip the breaker", err)
+	}
+	if calls != 2 {
+		t.Fatalf("upstream called %d times, want 2 before the breaker opened", calls)
+	}
+
+	if _, err := tr.RoundTrip(req); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("err = %v, want ErrCircuitOpen", err)
+	}
+	if calls != 2 {
+		t.Errorf("upstream called while the breaker was open")
+	}
+}
+
+func TestTransportReplaysRequestBody(t *testing.T) {
+	var bodies []string
+	tr := NewTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) < 3 {
+			return statusResponse(http.StatusBadGateway), nil
+		}
+		return statusResponse(http.StatusOK), nil
+	}), newTestBreaker(100, time.Hour), testPolicy)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://upstream.test/", strings.NewReader(`{"q":1}`))
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+	for i, b := range bodies {
+		if b != `{"q":1}` {
+			t.Errorf("attempt %d sent body %q", i+1, b)
+		}
+	}
+	if len(bodies) != 3 {
+		t.Errorf("made %d attempts, want 3", len(bodies))
+	}
+}
+
+func TestTransportDoesNotRetryBodyItCannotReplay(t *testing.T) {
+	calls := 0
+	tr := NewTransport(roundTripFunc(func(*http.Request) (*http.Response, error) {
+		calls++
+		return statusResponse(http.StatusBadGateway), nil
+	}), newTestBreaker(100, time.Hour), testPolicy)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://upstream.test/", nil)
+	req.Body = io.NopCloser(strings.NewReader("once"))
+
+	if _, err := tr.RoundTrip(req); err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("upstream called %d times with a body that cannot be replayed", calls)
+	}
+}
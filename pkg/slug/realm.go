@@ -0,0 +1,100 @@
+package slug
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type Realm struct {
+	ID    int               `json:"id"`
+	Slug  string            `json:"slug"`
+	Names map[string]string `json:"names"`
+}
+
+// RealmIndex maps realm slugs, IDs and every localized realm name to the same
+// realm. It is safe for concurrent use and is reloaded as a whole.
+type RealmIndex struct {
+	mu       sync.RWMutex
+	byID     map[int]Realm
+	bySlug   map[string]Realm
+	byName   map[string]Realm
+	loadedAt time.Time
+}
+
+func NewRealmIndex() *RealmIndex {
+	return &RealmIndex{
+		byID:   make(map[int]Realm),
+		bySlug: make(map[string]Realm),
+		byName: make(map[string]Realm),
+	}
+}
+
+func (ri *RealmIndex) Load(realms []Realm) {
+	byID := make(map[int]Realm, len(realms))
+	bySlug := make(map[string]Realm, len(realms))
+	byName := make(map[string]Realm, len(realms))
+
+	for _, r := range realms {
+		byID[r.ID] = r
+		bySlug[r.Slug] = r
+		for _, name := range r.Names {
+			if name == "" {
+				continue
+			}
+			byName[Make(name)] = r
+		}
+	}
+
+	ri.mu.Lock()
+	defer ri.mu.Unlock()
+	ri.byID = byID
+	ri.bySlug = bySlug
+	ri.byName = byName
+	ri.loadedAt = time.Now()
+}
+
+// Resolve looks a realm up by its slug first and then by any of its localized
+// names, so both "howling-fjord" and "Ревущий фьорд" find the same realm.
+func (ri *RealmIndex) Resolve(nameOrSlug string) (Realm, bool) {
+	ri.mu.RLock()
+	defer ri.mu.RUnlock()
+
+	key := strings.ToLower(strings.TrimSpace(nameOrSlug))
+	if r, ok := ri.bySlug[key]; ok {
+		return r, true
+	}
+	r, ok := ri.byName[Make(nameOrSlug)]
+	return r, ok
+}
+
+func (ri *RealmIndex) ByID(id int) (Realm, bool) {
+	ri.mu.RLock()
+	defer ri.mu.RUnlock()
+
+	r, ok := ri.byID[id]
+	return r, ok
+}
+
+// Slug returns the canonical slug of a known realm and falls back to the
+// generic slug rules when the realm is missing from the index.
+func (ri *RealmIndex) Slug(nameOrSlug string) string {
+	if r, ok := ri.Resolve(nameOrSlug); ok {
+		return r.Slug
+	}
+	return Make(nameOrSlug)
+}
+
+func (ri *RealmIndex) Len() int {
+	ri.mu.RLock()
+	defer ri.mu.RUnlock()
+
+	return len(ri.byID)
+}
+
+func (ri *RealmIndex) LoadedAt() time.Time {
+	ri.mu.RLock()
+	defer ri.mu.RUnlock()
+
+	return ri.loadedAt
+}
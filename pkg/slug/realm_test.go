@@ -0,0 +1,81 @@
+package slug
+
+import "testing"
+
+func testRealms() []Realm {
+	return []Realm{
+		{ID: 1, Slug: "howling-fjord", Names: map[string]string{"en_GB": "Howling Fjord", "ru_RU": "Ревущий фьорд"}},
+		{ID: 2, Slug: "kelthuzad", Names: map[string]string{"en_US": "Kel'Thuzad"}},
+		{ID: 3, Slug: "aggra-portugues", Names: map[string]string{"en_GB": "Aggra (Português)", "pt_BR": ""}},
+	}
+}
+
+func TestRealmIndexResolve(t *testing.T) {
+	ri := NewRealmIndex()
+	ri.Load(testRealms())
+
+	tests := []struct {
+		in     string
+		wantID int
+	}{
+		{"howling-fjord", 1},
+		{"  Howling-Fjord ", 1},
+		{"Howling Fjord", 1},
+		{"Ревущий фьорд", 1},
+		{"ревущий-фьорд", 1},
+		{"Kel'Thuzad", 2},
+		{"Aggra (Português)", 3},
+		{"Silvermoon", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			r, ok := ri.Resolve(tt.in)
+			if tt.wantID == 0 {
+				if ok {
+					t.Errorf("Resolve(%q) = %+v, want a miss", tt.in, r)
+				}
+				return
+			}
+			if !ok || r.ID != tt.wantID {
+				t.Errorf("Resolve(%q) = %+v, %v, want realm %d", tt.in, r, ok, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestRealmIndexSlugFallsBackOnMiss(t *testing.T) {
+	ri := NewRealmIndex()
+	ri.Load(testRealms())
+
+	if got := ri.Slug("Ревущий фьорд"); got != "howling-fjord" {
+		t.Errorf("Slug of a known localized name = %q, want howling-fjord", got)
+	}
+	if got := ri.Slug("Twisting Nether"); got != "twisting-nether" {
+		t.Errorf("Slug of an unknown realm = %q, want twisting-nether", got)
+	}
+}
+
+func TestRealmIndexLoadReplacesTheIndex(t *testing.T) {
+	ri := NewRealmIndex()
+	if ri.Len() != 0 || !ri.LoadedAt().IsZero() {
+		t.Fatalf("new index has %d realms, loaded at %s", ri.Len(), ri.LoadedAt())
+	}
+
+	ri.Load(testRealms())
+	if ri.Len() != 3 || ri.LoadedAt().IsZero() {
+		t.Fatalf("loaded index has %d realms, loaded at %s", ri.Len(), ri.LoadedAt())
+	}
+	if r, ok := ri.ByID(2); !ok || r.Slug != "kelthuzad" {
+		t.Errorf("ByID(2) = %+v, %v", r, ok)
+	}
+
+	ri.Load(testRealms()[:1])
+	if _, ok := ri.ByID(2); ok {
+		t.Error("realm 2 survived a reload without it")
+	}
+	if _, ok := ri.Resolve("Kel'Thuzad"); ok {
+		t.Error("name of realm 2 survived a reload without it")
+	}
+}
@@ -0,0 +1,57 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var dropped = map[rune]struct{}{
+	'\'': {}, '’': {}, '‘': {}, '`': {}, '´': {},
+	'(': {}, ')': {}, '[': {}, ']': {},
+	'.': {}, ',': {}, ':': {}, '!': {}, '?': {},
+	'"': {}, '«': {}, '»': {},
+}
+
+// Make builds a slug the way Blizzard does for realm and guild names:
+// lower-cased, apostrophes and punctuation removed, whitespace collapsed into
+// single dashes. Accents are stripped from Latin letters only, so Cyrillic
+// names keep letters such as "й" and "ё" intact.
+func Make(name string) string {
+	var b strings.Builder
+	b.Grow(len(name))
+
+	latinBase := false
+	pendingDash := false
+	for _, r := range norm.NFD.String(strings.TrimSpace(name)) {
+		if unicode.Is(unicode.Mn, r) {
+			if !latinBase {
+				b.WriteRune(r)
+			}
+			continue
+		}
+
+		if _, ok := dropped[r]; ok {
+			continue
+		}
+
+		if unicode.IsSpace(r) || r == '-' || r == '_' {
+			pendingDash = b.Len() > 0
+			continue
+		}
+
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			continue
+		}
+
+		if pendingDash {
+			b.WriteByte('-')
+			pendingDash = false
+		}
+		latinBase = unicode.Is(unicode.Latin, r)
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return norm.NFC.String(b.String())
+}
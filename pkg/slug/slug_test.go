@@ -0,0 +1,35 @@
+package slug
+
+import "testing"
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"apostrophe", "Kel'Thuzad", "kelthuzad"},
+		{"typographic apostrophe", "Mal’Ganis", "malganis"},
+		{"latin accents and parentheses", "Aggra (Português)", "aggra-portugues"},
+		{"german umlaut", "Festung der Stürme", "festung-der-sturme"},
+		{"french accents", "Confrérie du Thorium", "confrerie-du-thorium"},
+		{"cyrillic", "Гордунни", "гордунни"},
+		{"cyrillic keeps short i", "Ясеневый лес", "ясеневый-лес"},
+		{"cyrillic keeps yo", "Чёрный Шрам", "чёрный-шрам"},
+		{"whitespace collapsed", "  Argent   Dawn\t", "argent-dawn"},
+		{"dashes collapsed", "Azjol -- Nerub", "azjol-nerub"},
+		{"underscore as dash", "Twisting_Nether", "twisting-nether"},
+		{"leading and trailing dashes dropped", "-Silvermoon-", "silvermoon"},
+		{"punctuation dropped", "Guild, Inc.!", "guild-inc"},
+		{"digits kept", "Area 52", "area-52"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Make(tt.in); got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"profile-service/pkg/config"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// restoreGlobals puts back the tracer provider and propagator Init replaces.
+func restoreGlobals(t *testing.T) {
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+}
+
+func testLogger() *logrus.Logger {
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+	return log
+}
+
+func TestInitDisabledStillForwardsTraceContext(t *testing.T) {
+	restoreGlobals(t)
+	provider := otel.GetTracerProvider()
+
+	var cfg config.Config
+	shutdown, err := Init(context.Background(), &cfg, testLogger())
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+	if otel.GetTracerProvider() != provider {
+		t.Error("disabled tracing replaced the tracer provider")
+	}
+
+	// A trace started upstream reaches auth_service through the outbound
+	// transport even though this service records nothing.
+	incoming := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), incoming)
+
+	var sent string
+	client := &http.Client{Transport: otelhttp.NewTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req.Header.Get("traceparent")
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}))}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://auth-service/user/blizzard-token", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	if sent != want {
+		t.Errorf("traceparent = %q, want %q", sent, want)
+	}
+}
+
+func TestInitFileExporterWritesSpans(t *testing.T) {
+	restoreGlobals(t)
+
+	var cfg config.Config
+	cfg.Tracing.Enabled = true
+	cfg.Tracing.Exporter = ExporterFile
+	cfg.Tracing.File = filepath.Join(t.TempDir(), "traces.json")
+	cfg.Tracing.SampleRatio = 1
+	cfg.Tracing.ServiceName = "profile-service-test"
+
+	shutdown, err := Init(context.Background(), &cfg, testLogger())
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	_, span := otel.Tracer("tracing_test").Start(context.Background(), "usecase.RefreshCharacters")
+	span.End()
+
+	// Spans are batched, so nothing is guaranteed on disk before shutdown.
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	data, err := os.ReadFile(cfg.Tracing.File)
+	if err != nil {
+		t.Fatalf("read trace file: %v", err)
+	}
+	for _, want := range []string{`"Name":"usecase.RefreshCharacters"`, `"Value":"profile-service-test"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("trace file lacks %s:\n%s", want, data)
+		}
+	}
+}
+
+func TestInitRejectsUnknownExporter(t *testing.T) {
+	restoreGlobals(t)
+
+	var cfg config.Config
+	cfg.Tracing.Enabled = true
+	cfg.Tracing.Exporter = "zipkin"
+
+	if _, err := Init(context.Background(), &cfg, testLogger()); err == nil || !strings.Contains(err.Error(), `"zipkin"`) {
+		t.Errorf("Init = %v, want an unknown exporter error", err)
+	}
+}
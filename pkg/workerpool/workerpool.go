@@ -0,0 +1,124 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Gauge is the part of a metrics gauge the pool reports its queue depth to.
+type Gauge interface {
+	Add(float64)
+}
+
+type Pool struct {
+	mu      sync.RWMutex
+	workers int
+	timeout time.Duration
+	queue   Gauge
+}
+
+// New returns a pool running at most workers jobs at once. A positive timeout
+// bounds every single job; it does not limit the whole run.
+func New(workers int, timeout time.Duration) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Pool{workers: workers, timeout: timeout}
+}
+
+// WithQueueGauge reports the number of items waiting for a free worker.
+func (p *Pool) WithQueueGauge(g Gauge) *Pool {
+	p.queue = g
+	return p
+}
+
+func (p *Pool) queued(delta int) {
+	if p.queue != nil {
+		p.queue.Add(float64(delta))
+	}
+}
+
+// Resize changes the limits for runs started afterwards; runs in flight keep
+// the values they started with.
+func (p *Pool) Resize(workers int, timeout time.Duration) {
+	if workers < 1 {
+		workers = 1
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.workers = workers
+	p.timeout = timeout
+}
+
+func (p *Pool) Workers() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.workers
+}
+
+func (p *Pool) limits() (int, time.Duration) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.workers, p.timeout
+}
+
+// Map calls fn for every item and returns the results in input order.
+// If ctx is cancelled before all items are processed the partial results
+// are discarded and ctx.Err() is returned.
+func Map[T, R any](ctx context.Context, p *Pool, items []T, fn func(ctx context.Context, item T) R) ([]R, error) {
+	results := make([]R, len(items))
+	if len(items) == 0 {
+		return results, ctx.Err()
+	}
+
+	workers, timeout := p.limits()
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	p.queued(len(items))
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				p.queued(-1)
+				results[idx] = runOne(ctx, timeout, items[idx], fn)
+			}
+		}()
+	}
+
+	sent := 0
+feed:
+	for range items {
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- sent:
+			sent++
+		}
+	}
+	close(jobs)
+	p.queued(sent - len(items))
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func runOne[T, R any](ctx context.Context, timeout time.Duration, item T, fn func(ctx context.Context, item T) R) R {
+	if timeout <= 0 {
+		return fn(ctx, item)
+	}
+
+	itemCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return fn(itemCtx, item)
+}
@@ -0,0 +1,232 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// gauge sums what the pool reports as queued.
+type gauge struct {
+	mu sync.Mutex
+	v  float64
+}
+
+func (g *gauge) Add(d float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.v += d
+}
+
+func (g *gauge) value() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.v
+}
+
+// peak tracks how many jobs run at the same time.
+type peak struct {
+	running, max atomic.Int32
+}
+
+func (p *peak) enter() {
+	n := p.running.Add(1)
+	for {
+		m := p.max.Load()
+		if n <= m || p.max.CompareAndSwap(m, n) {
+			return
+		}
+	}
+}
+
+func (p *peak) leave() { p.running.Add(-1) }
+
+func TestMapKeepsInputOrder(t *testing.T) {
+	items := make([]int, 200)
+	for i := range items {
+		items[i] = i
+	}
+
+	q := &gauge{}
+	p := New(8, 0).WithQueueGauge(q)
+	got, err := Map(context.Background(), p, items, func(_ context.Context, n int) int {
+		time.Sleep(time.Duration(rand.Intn(500)) * time.Microsecond)
+		return n * n
+	})
+	if err != nil {
+		t.Fatalf("Map: %v", err)
+	}
+
+	for i, v := range got {
+		if v != i*i {
+			t.Fatalf("result %d = %d, want %d", i, v, i*i)
+		}
+	}
+	if q.value() != 0 {
+		t.Errorf("queue gauge = %v after the run, want 0", q.value())
+	}
+}
+
+func TestMapWorkerLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		workers  int
+		items    int
+		wantPeak int32
+	}{
+		{name: "zero workers run one at a time", workers: 0, items: 5, wantPeak: 1},
+		{name: "negative workers run one at a time", workers: -3, items: 5, wantPeak: 1},
+		{name: "limit respected", workers: 3, items: 12, wantPeak: 3},
+		{name: "more workers than items", workers: 10, items: 4, wantPeak: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pk peak
+			got, err := Map(context.Background(), New(tt.workers, 0), make([]int, tt.items), func(_ context.Context, _ int) bool {
+				pk.enter()
+				defer pk.leave()
+				time.Sleep(5 * time.Millisecond)
+				return true
+			})
+			if err != nil {
+				t.Fatalf("Map: %v", err)
+			}
+			if len(got) != tt.items {
+				t.Fatalf("got %d results, want %d", len(got), tt.items)
+			}
+			if m := pk.max.Load(); m > tt.wantPeak {
+				t.Errorf("%d jobs ran at once, limit %d", m, tt.wantPeak)
+			}
+		})
+	}
+}
+
+func TestMapEmpty(t *testing.T) {
+	got, err := Map(context.Background(), New(4, 0), []int(nil), func(_ context.Context, n int) int { return n })
+	if err != nil || len(got) != 0 {
+		t.Errorf("Map(nil) = %v, %v", got, err)
+	}
+}
+
+func TestMapCancelledMidFeed(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	q := &gauge{}
+	p := New(2, 0).WithQueueGauge(q)
+	var calls atomic.Int32
+	got, err := Map(ctx, p, make([]int, 100), func(ctx context.Context, _ int) int {
+		if calls.Add(1) == 2 {
+			cancel()
+		}
+		<-ctx.Done()
+		return 1
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if got != nil {
+		t.Errorf("partial results returned: %d", len(got))
+	}
+	if n := calls.Load(); n >= 100 {
+		t.Errorf("%d items ran after cancellation", n)
+	}
+	if q.value() != 0 {
+		t.Errorf("queue gauge = %v after cancellation, want 0", q.value())
+	}
+
+	// Map waits for its workers, so none may outlive the call.
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("%d goroutines after Map, %d before", n, before)
+	}
+}
+
+func TestMapJobTimeout(t *testing.T) {
+	got, err := Map(context.Background(), New(2, 10*time.Millisecond), []int{1, 2}, func(ctx context.Context, _ int) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Map: %v", err)
+	}
+	for i, e := range got {
+		if !errors.Is(e, context.DeadlineExceeded) {
+			t.Errorf("job %d ended with %v, want its own deadline", i, e)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	p := New(1, 0)
+	p.Resize(4, time.Second)
+	if p.Workers() != 4 {
+		t.Errorf("Workers() = %d after Resize(4)", p.Workers())
+	}
+	if _, timeout := p.limits(); timeout != time.Second {
+		t.Errorf("timeout = %s after Resize", timeout)
+	}
+
+	var pk peak
+	if _, err := Map(context.Background(), p, make([]int, 8), func(_ context.Context, _ int) int {
+		pk.enter()
+		defer pk.leave()
+		time.Sleep(20 * time.Millisecond)
+		return 0
+	}); err != nil {
+		t.Fatalf("Map: %v", err)
+	}
+	if m := pk.max.Load(); m > 4 {
+		t.Errorf("%d jobs ran at once after Resize(4)", m)
+	}
+
+	p.Resize(0, 0)
+	if p.Workers() != 1 {
+		t.Errorf("Workers() = %d after Resize(0), want 1", p.Workers())
+	}
+}
+
+func TestResizeLeavesRunningMapAlone(t *testing.T) {
+	p := New(2, 0)
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	var pk peak
+	done := make(chan error)
+	go func() {
+		_, err := Map(context.Background(), p, make([]int, 6), func(_ context.Context, _ int) int {
+			pk.enter()
+			defer pk.leave()
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-release
+			return 0
+		})
+		done <- err
+	}()
+
+	<-started
+	p.Resize(6, 0)
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Fatalf("Map: %v", err)
+	}
+	if m := pk.max.Load(); m > 2 {
+		t.Errorf("%d jobs ran at once, the run started with 2 workers", m)
+	}
+}